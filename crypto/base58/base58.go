@@ -0,0 +1,131 @@
+package base58
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// alphabet is the modified base58 alphabet used by Bitcoin.
+const alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+var (
+	// ErrInvalidFormat is returned when a string contains characters that
+	// are not in the base58 alphabet or is too short to hold a checksum.
+	ErrInvalidFormat = errors.New("invalid base58 format")
+
+	// ErrChecksum is returned when the checksum of a base58check string
+	// does not match.
+	ErrChecksum = errors.New("base58 checksum mismatch")
+)
+
+var (
+	bigRadix = big.NewInt(58)
+	bigZero  = big.NewInt(0)
+
+	decodeMap [256]int8
+)
+
+func init() {
+	for i := range decodeMap {
+		decodeMap[i] = -1
+	}
+	for i := 0; i < len(alphabet); i++ {
+		decodeMap[alphabet[i]] = int8(i)
+	}
+}
+
+// Encode encodes a byte slice to a modified base58 string.  Each leading zero
+// byte is encoded as a leading '1'.
+func Encode(b []byte) string {
+	x := new(big.Int).SetBytes(b)
+
+	answer := make([]byte, 0, len(b)*138/100+1)
+	mod := new(big.Int)
+	for x.Cmp(bigZero) > 0 {
+		x.DivMod(x, bigRadix, mod)
+		answer = append(answer, alphabet[mod.Int64()])
+	}
+
+	// leading zero bytes
+	for _, i := range b {
+		if i != 0 {
+			break
+		}
+		answer = append(answer, alphabet[0])
+	}
+
+	// reverse
+	alen := len(answer)
+	for i := 0; i < alen/2; i++ {
+		answer[i], answer[alen-1-i] = answer[alen-1-i], answer[i]
+	}
+
+	return string(answer)
+}
+
+// Decode decodes a modified base58 string to a byte slice.
+func Decode(s string) ([]byte, error) {
+	answer := big.NewInt(0)
+	j := big.NewInt(1)
+
+	scratch := new(big.Int)
+	for i := len(s) - 1; i >= 0; i-- {
+		tmp := decodeMap[s[i]]
+		if tmp == -1 {
+			return nil, ErrInvalidFormat
+		}
+		scratch.SetInt64(int64(tmp))
+		scratch.Mul(j, scratch)
+		answer.Add(answer, scratch)
+		j.Mul(j, bigRadix)
+	}
+
+	tmpval := answer.Bytes()
+
+	var numZeros int
+	for numZeros = 0; numZeros < len(s); numZeros++ {
+		if s[numZeros] != alphabet[0] {
+			break
+		}
+	}
+	flen := numZeros + len(tmpval)
+	val := make([]byte, flen)
+	copy(val[numZeros:], tmpval)
+
+	return val, nil
+}
+
+// checksum returns the first four bytes of the double SHA256 of the input.
+func checksum(input []byte) []byte {
+	h := sha256.Sum256(input)
+	h2 := sha256.Sum256(h[:])
+	return h2[:4]
+}
+
+// CheckEncode appends a four byte checksum to the input and encodes the
+// result as a base58 string.
+func CheckEncode(input []byte) string {
+	b := make([]byte, 0, len(input)+4)
+	b = append(b, input...)
+	b = append(b, checksum(input)...)
+	return Encode(b)
+}
+
+// CheckDecode decodes a string that was encoded with CheckEncode and verifies
+// the checksum.  The checksum is stripped from the returned bytes.
+func CheckDecode(s string) ([]byte, error) {
+	decoded, err := Decode(s)
+	if err != nil {
+		return nil, err
+	}
+	if len(decoded) < 4 {
+		return nil, ErrInvalidFormat
+	}
+	payload := decoded[:len(decoded)-4]
+	if !bytes.Equal(checksum(payload), decoded[len(decoded)-4:]) {
+		return nil, ErrChecksum
+	}
+	return payload, nil
+}
@@ -0,0 +1,187 @@
+package bech32
+
+import (
+	"errors"
+	"strings"
+)
+
+// charset is the set of characters used in the data section of bech32
+// strings.  Every character maps to the 5-bit value of its index.
+const charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+var gen = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+var (
+	// ErrInvalidFormat is returned when a bech32 string is malformed.
+	ErrInvalidFormat = errors.New("invalid bech32 format")
+
+	// ErrChecksum is returned when the checksum of a bech32 string does
+	// not match.
+	ErrChecksum = errors.New("bech32 checksum mismatch")
+
+	// ErrInvalidSegwit is returned when a segwit address has an invalid
+	// witness version or program.
+	ErrInvalidSegwit = errors.New("invalid segwit address")
+)
+
+// polymod calculates the BCH checksum of the 5-bit values.
+func polymod(values []byte) uint32 {
+	chk := uint32(1)
+	for _, v := range values {
+		b := chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := 0; i < 5; i++ {
+			if (b>>uint(i))&1 == 1 {
+				chk ^= gen[i]
+			}
+		}
+	}
+	return chk
+}
+
+// hrpExpand expands the human readable part for use in the checksum.
+func hrpExpand(hrp string) []byte {
+	v := make([]byte, 0, len(hrp)*2+1)
+	for i := 0; i < len(hrp); i++ {
+		v = append(v, hrp[i]>>5)
+	}
+	v = append(v, 0)
+	for i := 0; i < len(hrp); i++ {
+		v = append(v, hrp[i]&31)
+	}
+	return v
+}
+
+// createChecksum returns the six 5-bit checksum values for the data.
+func createChecksum(hrp string, data []byte) []byte {
+	values := append(hrpExpand(hrp), data...)
+	values = append(values, 0, 0, 0, 0, 0, 0)
+	mod := polymod(values) ^ 1
+	checksum := make([]byte, 6)
+	for i := range checksum {
+		checksum[i] = byte(mod>>uint(5*(5-i))) & 31
+	}
+	return checksum
+}
+
+// Encode encodes the human readable part and the 5-bit data values as a
+// bech32 string.
+func Encode(hrp string, data []byte) (string, error) {
+	if len(hrp) == 0 || len(hrp)+len(data)+7 > 90 {
+		return "", ErrInvalidFormat
+	}
+	hrp = strings.ToLower(hrp)
+	combined := append(append([]byte(nil), data...), createChecksum(hrp, data)...)
+
+	var sb strings.Builder
+	sb.Grow(len(hrp) + 1 + len(combined))
+	sb.WriteString(hrp)
+	sb.WriteByte('1')
+	for _, v := range combined {
+		if int(v) >= len(charset) {
+			return "", ErrInvalidFormat
+		}
+		sb.WriteByte(charset[v])
+	}
+	return sb.String(), nil
+}
+
+// Decode decodes a bech32 string into its human readable part and 5-bit data
+// values, verifying the checksum.
+func Decode(s string) (string, []byte, error) {
+	if len(s) < 8 || len(s) > 90 {
+		return "", nil, ErrInvalidFormat
+	}
+	// Mixed case strings are invalid.
+	lower := strings.ToLower(s)
+	if s != lower && s != strings.ToUpper(s) {
+		return "", nil, ErrInvalidFormat
+	}
+	s = lower
+
+	pos := strings.LastIndexByte(s, '1')
+	if pos < 1 || pos+7 > len(s) {
+		return "", nil, ErrInvalidFormat
+	}
+	hrp := s[:pos]
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return "", nil, ErrInvalidFormat
+		}
+	}
+	data := make([]byte, 0, len(s)-pos-1)
+	for i := pos + 1; i < len(s); i++ {
+		v := strings.IndexByte(charset, s[i])
+		if v < 0 {
+			return "", nil, ErrInvalidFormat
+		}
+		data = append(data, byte(v))
+	}
+	if polymod(append(hrpExpand(hrp), data...)) != 1 {
+		return "", nil, ErrChecksum
+	}
+	return hrp, data[:len(data)-6], nil
+}
+
+// ConvertBits regroups a slice of fromBits-bit values into toBits-bit values.
+// With pad set, the trailing bits are zero padded, otherwise any leftover
+// bits must be zero padding of less than fromBits bits.
+func ConvertBits(data []byte, fromBits, toBits uint, pad bool) ([]byte, error) {
+	var acc uint32
+	var bits uint
+	maxv := uint32(1)<<toBits - 1
+	out := make([]byte, 0, len(data)*int(fromBits)/int(toBits)+1)
+	for _, v := range data {
+		if uint32(v)>>fromBits != 0 {
+			return nil, ErrInvalidFormat
+		}
+		acc = acc<<fromBits | uint32(v)
+		bits += fromBits
+		for bits >= toBits {
+			bits -= toBits
+			out = append(out, byte(acc>>bits&maxv))
+		}
+	}
+	if pad {
+		if bits > 0 {
+			out = append(out, byte(acc<<(toBits-bits)&maxv))
+		}
+	} else if bits >= fromBits || acc<<(toBits-bits)&maxv != 0 {
+		return nil, ErrInvalidFormat
+	}
+	return out, nil
+}
+
+// EncodeSegwit encodes a segwit version 0 witness program as a BIP-173
+// address with the human readable part hrp, such as "bc".
+func EncodeSegwit(hrp string, version byte, program []byte) (string, error) {
+	if version != 0 || (len(program) != 20 && len(program) != 32) {
+		return "", ErrInvalidSegwit
+	}
+	data, err := ConvertBits(program, 8, 5, true)
+	if err != nil {
+		return "", err
+	}
+	return Encode(hrp, append([]byte{version}, data...))
+}
+
+// DecodeSegwit decodes a BIP-173 segwit address with the human readable part
+// hrp and returns its witness version and program.
+func DecodeSegwit(hrp, addr string) (byte, []byte, error) {
+	gotHRP, data, err := Decode(addr)
+	if err != nil {
+		return 0, nil, err
+	}
+	if gotHRP != strings.ToLower(hrp) || len(data) < 1 {
+		return 0, nil, ErrInvalidSegwit
+	}
+	program, err := ConvertBits(data[1:], 5, 8, false)
+	if err != nil {
+		return 0, nil, err
+	}
+	version := data[0]
+	if version != 0 || (len(program) != 20 && len(program) != 32) {
+		return 0, nil, ErrInvalidSegwit
+	}
+	return version, program, nil
+}
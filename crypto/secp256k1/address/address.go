@@ -0,0 +1,170 @@
+package address
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"github.com/w3liu/go-common/crypto/base58"
+	"github.com/w3liu/go-common/crypto/bech32"
+	"github.com/w3liu/go-common/crypto/secp256k1"
+	"golang.org/x/crypto/ripemd160"
+	"golang.org/x/crypto/sha3"
+	"strings"
+)
+
+const (
+	// EthereumAddressLen is the length in bytes of an Ethereum address.
+	EthereumAddressLen = 20
+
+	// PubKeyHashLen is the length in bytes of the HASH160 of a public key
+	// used by P2PKH and P2WPKH addresses.
+	PubKeyHashLen = 20
+)
+
+var (
+	// ErrInvalidAddress is returned when an address string is malformed or
+	// belongs to another network.
+	ErrInvalidAddress = errors.New("invalid address")
+
+	// ErrChecksum is returned when the checksum of an address does not
+	// match.
+	ErrChecksum = errors.New("address checksum mismatch")
+)
+
+// Network holds the parameters that make Bitcoin addresses unique to a
+// network.
+type Network struct {
+	// PubKeyHashAddrID is the version byte of P2PKH addresses.
+	PubKeyHashAddrID byte
+
+	// Bech32HRP is the human readable part of segwit addresses.
+	Bech32HRP string
+}
+
+var (
+	// MainNet holds the address parameters of the Bitcoin main network.
+	MainNet = &Network{PubKeyHashAddrID: 0x00, Bech32HRP: "bc"}
+
+	// TestNet holds the address parameters of the Bitcoin test network.
+	TestNet = &Network{PubKeyHashAddrID: 0x6f, Bech32HRP: "tb"}
+)
+
+// Hash160 calculates RIPEMD160(SHA256(b)).
+func Hash160(b []byte) []byte {
+	h := sha256.Sum256(b)
+	r := ripemd160.New()
+	r.Write(h[:])
+	return r.Sum(nil)
+}
+
+// keccak256 calculates the Keccak-256 hash used by Ethereum, which differs
+// from the standardized SHA3-256 in its padding.
+func keccak256(b []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(b)
+	return h.Sum(nil)
+}
+
+// EthereumAddress returns the EIP-55 checksummed Ethereum address of the
+// public key, which is the last 20 bytes of the Keccak-256 hash of the
+// uncompressed public key without its prefix byte.
+func EthereumAddress(pubKey *secp256k1.PublicKey) string {
+	h := keccak256(pubKey.SerializeUncompressed()[1:])
+	return checksumEthereum(h[len(h)-EthereumAddressLen:])
+}
+
+// checksumEthereum hex encodes the address with the EIP-55 mixed case
+// checksum: a letter is upper case when the corresponding nibble of the
+// Keccak-256 hash of the lower case hex address is 8 or more.
+func checksumEthereum(addr []byte) string {
+	buf := []byte(hex.EncodeToString(addr))
+	h := keccak256(buf)
+	for i, c := range buf {
+		if c < 'a' {
+			continue
+		}
+		nibble := h[i/2]
+		if i%2 == 0 {
+			nibble >>= 4
+		}
+		if nibble&0xf >= 8 {
+			buf[i] = c - 'a' + 'A'
+		}
+	}
+	return "0x" + string(buf)
+}
+
+// ParseEthereumAddress decodes a hex Ethereum address with or without the
+// "0x" prefix.  All lower or all upper case addresses carry no checksum and
+// are accepted as is, mixed case addresses must have a valid EIP-55 checksum.
+func ParseEthereumAddress(s string) ([]byte, error) {
+	if strings.HasPrefix(s, "0x") || strings.HasPrefix(s, "0X") {
+		s = s[2:]
+	}
+	if len(s) != 2*EthereumAddressLen {
+		return nil, ErrInvalidAddress
+	}
+	addr, err := hex.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidAddress
+	}
+	if s != strings.ToLower(s) && s != strings.ToUpper(s) &&
+		checksumEthereum(addr)[2:] != s {
+		return nil, ErrChecksum
+	}
+	return addr, nil
+}
+
+// IsValidEthereumAddress reports whether s is a valid Ethereum address.
+func IsValidEthereumAddress(s string) bool {
+	_, err := ParseEthereumAddress(s)
+	return err == nil
+}
+
+// P2PKHAddress returns the Base58Check pay-to-pubkey-hash address of the
+// public key on the network.  The address commits to the serialized form of
+// the key, so the compressed and uncompressed forms have different addresses.
+func P2PKHAddress(pubKey *secp256k1.PublicKey, compressed bool, net *Network) string {
+	var serialized []byte
+	if compressed {
+		serialized = pubKey.SerializeCompressed()
+	} else {
+		serialized = pubKey.SerializeUncompressed()
+	}
+	b := make([]byte, 0, 1+PubKeyHashLen)
+	b = append(b, net.PubKeyHashAddrID)
+	b = append(b, Hash160(serialized)...)
+	return base58.CheckEncode(b)
+}
+
+// DecodeP2PKHAddress decodes a P2PKH address of the network, verifying its
+// checksum and version byte, and returns the 20-byte public key hash.
+func DecodeP2PKHAddress(addr string, net *Network) ([]byte, error) {
+	b, err := base58.CheckDecode(addr)
+	if err == base58.ErrChecksum {
+		return nil, ErrChecksum
+	}
+	if err != nil || len(b) != 1+PubKeyHashLen || b[0] != net.PubKeyHashAddrID {
+		return nil, ErrInvalidAddress
+	}
+	return b[1:], nil
+}
+
+// P2WPKHAddress returns the Bech32 pay-to-witness-pubkey-hash address of the
+// public key on the network.  Segwit only allows compressed public keys.
+func P2WPKHAddress(pubKey *secp256k1.PublicKey, net *Network) (string, error) {
+	return bech32.EncodeSegwit(net.Bech32HRP, 0, Hash160(pubKey.SerializeCompressed()))
+}
+
+// DecodeP2WPKHAddress decodes a P2WPKH address of the network, verifying its
+// checksum and witness version, and returns the 20-byte public key hash.
+func DecodeP2WPKHAddress(addr string, net *Network) ([]byte, error) {
+	_, program, err := bech32.DecodeSegwit(net.Bech32HRP, addr)
+	if err == bech32.ErrChecksum {
+		return nil, ErrChecksum
+	}
+	if err != nil || len(program) != PubKeyHashLen {
+		return nil, ErrInvalidAddress
+	}
+	return program, nil
+}
@@ -0,0 +1,128 @@
+package secp256k1
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"golang.org/x/crypto/hkdf"
+	"io"
+)
+
+var (
+	// ErrInvalidPubKey is returned when the peer public key is not a valid
+	// point on the curve.
+	ErrInvalidPubKey = errors.New("invalid public key")
+
+	// ErrInvalidCiphertext is returned when a ciphertext is malformed or
+	// fails authentication.
+	ErrInvalidCiphertext = errors.New("invalid ciphertext")
+)
+
+// eciesInfo is the HKDF info prefix binding derived keys to this scheme.
+var eciesInfo = []byte("secp256k1 ECIES AES-256-GCM")
+
+const (
+	eciesKeySize   = 32
+	eciesNonceSize = 12
+)
+
+// GenerateSharedSecret generates a shared secret based on a private key and a
+// public key using Diffie-Hellman key exchange (ECDH) (RFC 4753).
+// RFC5903 Section 9 states we should only return x.
+func GenerateSharedSecret(privKey *PrivateKey, pubKey *PublicKey) ([]byte, error) {
+	curve := S256()
+	if privKey == nil || privKey.D == nil || privKey.D.Sign() <= 0 ||
+		privKey.D.Cmp(curve.N) >= 0 {
+		return nil, errors.New("invalid private key")
+	}
+	if pubKey == nil || pubKey.X == nil || pubKey.Y == nil ||
+		(pubKey.X.Sign() == 0 && pubKey.Y.Sign() == 0) ||
+		!curve.IsOnCurve(pubKey.X, pubKey.Y) {
+		return nil, ErrInvalidPubKey
+	}
+
+	x, y := curve.ScalarMultConstantTime(pubKey.X, pubKey.Y, privKey.D.Bytes())
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return nil, ErrInvalidPubKey
+	}
+	return paddedAppend(32, nil, x.Bytes()), nil
+}
+
+// Encrypt encrypts data for the receiver public key using ECIES.  A fresh
+// ephemeral key is agreed with the receiver through ECDH, the AES-256-GCM key
+// is derived from the shared secret with HKDF-SHA256, and the result has the
+// format:
+//
+// <33 byte compressed ephemeral pubkey><12 byte nonce><ciphertext><16 byte tag>
+func Encrypt(pubKey *PublicKey, data []byte) ([]byte, error) {
+	ephemeral, err := GeneratePrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	ephemeralPub := ephemeral.PubKey().SerializeCompressed()
+
+	secret, err := GenerateSharedSecret(ephemeral, pubKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newECIESCipher(secret, ephemeralPub)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make([]byte, 0, PubKeyBytesLenCompressed+eciesNonceSize+
+		len(data)+aead.Overhead())
+	out = append(out, ephemeralPub...)
+	nonce := make([]byte, eciesNonceSize)
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	out = append(out, nonce...)
+	return aead.Seal(out, nonce, data, ephemeralPub), nil
+}
+
+// Decrypt decrypts data that was encrypted for the private key's public key
+// using Encrypt.
+func Decrypt(privKey *PrivateKey, in []byte) ([]byte, error) {
+	if len(in) < PubKeyBytesLenCompressed+eciesNonceSize {
+		return nil, ErrInvalidCiphertext
+	}
+	ephemeralPub := in[:PubKeyBytesLenCompressed]
+	nonce := in[PubKeyBytesLenCompressed : PubKeyBytesLenCompressed+eciesNonceSize]
+	ciphertext := in[PubKeyBytesLenCompressed+eciesNonceSize:]
+
+	pubKey, err := ParsePubKey(ephemeralPub)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	secret, err := GenerateSharedSecret(privKey, pubKey)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newECIESCipher(secret, ephemeralPub)
+	if err != nil {
+		return nil, err
+	}
+	data, err := aead.Open(nil, nonce, ciphertext, ephemeralPub)
+	if err != nil {
+		return nil, ErrInvalidCiphertext
+	}
+	return data, nil
+}
+
+// newECIESCipher derives the AES-256-GCM cipher for the shared secret.  The
+// ephemeral public key is part of the HKDF info so the key is bound to it.
+func newECIESCipher(secret, ephemeralPub []byte) (cipher.AEAD, error) {
+	info := append(append([]byte{}, eciesInfo...), ephemeralPub...)
+	key := make([]byte, eciesKeySize)
+	if _, err := io.ReadFull(hkdf.New(sha256.New, secret, nil, info), key); err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
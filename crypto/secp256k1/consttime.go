@@ -0,0 +1,264 @@
+package secp256k1
+
+import (
+	"math/big"
+	"sync"
+)
+
+// The functions in this file perform scalar multiplication without branches
+// or memory accesses that depend on the scalar.  They are used wherever the
+// scalar is secret, such as private keys and nonces, while ScalarMult and
+// ScalarBaseMult remain the faster choice for public data like signature
+// verification.
+//
+// The approach is a fixed 4-bit window: every window costs exactly the same
+// doublings and one addition, the table entry for the window is selected by
+// scanning the whole table with masked moves, and the point addition handles
+// the point at infinity and doubling through masked moves as well.
+
+const (
+	// ctWindowBits is the number of scalar bits consumed per window.
+	ctWindowBits = 4
+
+	// ctWindowSize is the number of table entries per window.
+	ctWindowSize = 1 << ctWindowBits
+
+	// ctWindows is the number of windows in a 256-bit scalar.
+	ctWindows = 256 / ctWindowBits
+)
+
+// ctBaseTable holds i*16^w*G for every window w and digit i, so scalar base
+// multiplication needs no doublings.  It is built on first use.
+var (
+	ctBaseTable     *[ctWindows][ctWindowSize][3]fieldVal
+	ctBaseTableOnce sync.Once
+)
+
+// ctEqual returns 1 when a equals b and 0 otherwise without branching.
+func ctEqual(a, b uint32) uint32 {
+	return uint32((uint64(a^b) - 1) >> 63)
+}
+
+// ctIsZero returns 1 when the normalized field value is zero and 0 otherwise
+// without branching.
+func (f *fieldVal) ctIsZero() uint32 {
+	bits := f.n[0] | f.n[1] | f.n[2] | f.n[3] | f.n[4] |
+		f.n[5] | f.n[6] | f.n[7] | f.n[8] | f.n[9]
+	return ctEqual(bits, 0)
+}
+
+// ctEquals returns 1 when the two normalized field values are the same and 0
+// otherwise without branching.
+func (f *fieldVal) ctEquals(val *fieldVal) uint32 {
+	bits := (f.n[0] ^ val.n[0]) | (f.n[1] ^ val.n[1]) | (f.n[2] ^ val.n[2]) |
+		(f.n[3] ^ val.n[3]) | (f.n[4] ^ val.n[4]) | (f.n[5] ^ val.n[5]) |
+		(f.n[6] ^ val.n[6]) | (f.n[7] ^ val.n[7]) | (f.n[8] ^ val.n[8]) |
+		(f.n[9] ^ val.n[9])
+	return ctEqual(bits, 0)
+}
+
+// cmov sets the field value to val when flag is 1 and leaves it unchanged when
+// flag is 0.  The flag must be 0 or 1.
+func (f *fieldVal) cmov(val *fieldVal, flag uint32) {
+	mask := -flag
+	for i := range f.n {
+		f.n[i] ^= mask & (f.n[i] ^ val.n[i])
+	}
+}
+
+// addConstantTime adds the Jacobian points (x1, y1, z1) and (x2, y2, z2) and
+// stores the result in (x3, y3, z3).  Unlike addJacobian it does not branch
+// on the inputs: the generic addition and the doubling of the first point are
+// always computed and the result is selected with masked moves.  A point with
+// a zero z value is the point at infinity.  The inputs must be normalized.
+func (curve *KoblitzCurve) addConstantTime(x1, y1, z1, x2, y2, z2, x3, y3, z3 *fieldVal) {
+	// This is the same add-2007-bl formula as addGeneric.  When the x
+	// coordinates are the same and the y coordinates are not, H is zero so
+	// Z3 comes out zero, which is the point at infinity as required.
+	var z1z1, z2z2, u1, u2, s1, s2 fieldVal
+	z1z1.SquareVal(z1)                        // Z1Z1 = Z1^2 (mag: 1)
+	z2z2.SquareVal(z2)                        // Z2Z2 = Z2^2 (mag: 1)
+	u1.Set(x1).Mul(&z2z2).Normalize()         // U1 = X1*Z2Z2 (mag: 1)
+	u2.Set(x2).Mul(&z1z1).Normalize()         // U2 = X2*Z1Z1 (mag: 1)
+	s1.Set(y1).Mul(&z2z2).Mul(z2).Normalize() // S1 = Y1*Z2*Z2Z2 (mag: 1)
+	s2.Set(y2).Mul(&z1z1).Mul(z1).Normalize() // S2 = Y2*Z1*Z1Z1 (mag: 1)
+
+	var h, i, j, r, rr, v fieldVal
+	var negU1, negS1, negX3 fieldVal
+	var rx, ry, rz fieldVal
+	negU1.Set(&u1).Negate(1)               // negU1 = -U1 (mag: 2)
+	h.Add2(&u2, &negU1)                    // H = U2-U1 (mag: 3)
+	i.Set(&h).MulInt(2).Square()           // I = (2*H)^2 (mag: 2)
+	j.Mul2(&h, &i)                         // J = H*I (mag: 1)
+	negS1.Set(&s1).Negate(1)               // negS1 = -S1 (mag: 2)
+	r.Set(&s2).Add(&negS1).MulInt(2)       // r = 2*(S2-S1) (mag: 6)
+	rr.SquareVal(&r)                       // rr = r^2 (mag: 1)
+	v.Mul2(&u1, &i)                        // V = U1*I (mag: 1)
+	rx.Set(&v).MulInt(2).Add(&j).Negate(3) // X3 = -(J+2*V) (mag: 4)
+	rx.Add(&rr)                            // X3 = r^2+X3 (mag: 5)
+	negX3.Set(&rx).Negate(5)               // negX3 = -X3 (mag: 6)
+	ry.Mul2(&s1, &j).MulInt(2).Negate(2)   // Y3 = -(2*S1*J) (mag: 3)
+	ry.Add(v.Add(&negX3).Mul(&r))          // Y3 = r*(V-X3)+Y3 (mag: 4)
+	rz.Add2(z1, z2).Square()               // Z3 = (Z1+Z2)^2 (mag: 1)
+	rz.Add(z1z1.Add(&z2z2).Negate(2))      // Z3 = Z3-(Z1Z1+Z2Z2) (mag: 4)
+	rz.Mul(&h)                             // Z3 = Z3*H (mag: 1)
+	rx.Normalize()
+	ry.Normalize()
+	rz.Normalize()
+
+	// The formula above divides by zero when both points are the same, so
+	// the doubling of the first point is selected in that case.
+	var dx, dy, dz fieldVal
+	curve.doubleGeneric(x1, y1, z1, &dx, &dy, &dz)
+	isDouble := u1.ctEquals(&u2) & s1.ctEquals(&s2)
+	rx.cmov(&dx, isDouble)
+	ry.cmov(&dy, isDouble)
+	rz.cmov(&dz, isDouble)
+
+	// ∞ + P = P and P + ∞ = P.
+	p1Inf := z1.ctIsZero()
+	rx.cmov(x2, p1Inf)
+	ry.cmov(y2, p1Inf)
+	rz.cmov(z2, p1Inf)
+	p2Inf := z2.ctIsZero()
+	rx.cmov(x1, p2Inf)
+	ry.cmov(y1, p2Inf)
+	rz.cmov(z1, p2Inf)
+
+	x3.Set(&rx)
+	y3.Set(&ry)
+	z3.Set(&rz)
+}
+
+// ctLookup sets (x, y, z) to table[digit] by scanning every entry of the
+// table, so the memory access pattern does not depend on the digit.
+func ctLookup(table *[ctWindowSize][3]fieldVal, digit uint32, x, y, z *fieldVal) {
+	x.Zero()
+	y.Zero()
+	z.Zero()
+	for i := range table {
+		flag := ctEqual(uint32(i), digit)
+		x.cmov(&table[i][0], flag)
+		y.cmov(&table[i][1], flag)
+		z.cmov(&table[i][2], flag)
+	}
+}
+
+// ctScalarBytes returns k as a 32-byte big endian integer.  Scalars longer
+// than 32 bytes are first reduced modulo the group order.  Shorter scalars
+// are left padded, which only depends on the length of k.
+func (curve *KoblitzCurve) ctScalarBytes(k []byte) [32]byte {
+	var b [32]byte
+	if len(k) > curve.byteSize {
+		k = curve.moduloReduce(k)
+	}
+	copy(b[32-len(k):], k)
+	return b
+}
+
+// ctDigit returns the 4-bit window w of the scalar, counting from the least
+// significant window.
+func ctDigit(k *[32]byte, w int) uint32 {
+	b := k[31-w/2]
+	if w%2 == 1 {
+		b >>= 4
+	}
+	return uint32(b & fourBitsMask)
+}
+
+// ScalarMultConstantTime returns k*(Bx, By) where k is a big endian integer.
+// It computes the same result as ScalarMult, but its running time and memory
+// access pattern do not depend on k, so it is safe to use with secret
+// scalars such as private keys.
+func (curve *KoblitzCurve) ScalarMultConstantTime(Bx, By *big.Int, k []byte) (*big.Int, *big.Int) {
+	qx, qy, qz := new(fieldVal), new(fieldVal), new(fieldVal)
+	px, py := curve.bigAffineToField(Bx, By)
+	curve.scalarMultConstantTimeJacobian(px, py, k, qx, qy, qz)
+	return curve.fieldJacobianToBigAffine(qx, qy, qz)
+}
+
+// scalarMultConstantTimeJacobian computes k*(px, py) in constant time where
+// (px, py) is an affine point, and stores the result as a Jacobian point in
+// (qx, qy, qz).
+func (curve *KoblitzCurve) scalarMultConstantTimeJacobian(px, py *fieldVal, k []byte, qx, qy, qz *fieldVal) {
+	// table[i] = i*P for every 4-bit digit i, with table[0] = ∞.
+	var table [ctWindowSize][3]fieldVal
+	table[1][0].Set(px)
+	table[1][1].Set(py)
+	table[1][2].SetInt(1)
+	for i := 2; i < ctWindowSize; i++ {
+		prev, t := &table[i-1], &table[i]
+		curve.addConstantTime(&prev[0], &prev[1], &prev[2],
+			&table[1][0], &table[1][1], &table[1][2], &t[0], &t[1], &t[2])
+	}
+
+	// Process the windows left-to-right: Q = 16*Q + table[digit].
+	kb := curve.ctScalarBytes(k)
+	qx.Zero()
+	qy.Zero()
+	qz.Zero()
+	var tx, ty, tz fieldVal
+	for w := ctWindows - 1; w >= 0; w-- {
+		for i := 0; i < ctWindowBits; i++ {
+			curve.doubleGeneric(qx, qy, qz, qx, qy, qz)
+		}
+		ctLookup(&table, ctDigit(&kb, w), &tx, &ty, &tz)
+		curve.addConstantTime(qx, qy, qz, &tx, &ty, &tz, qx, qy, qz)
+	}
+}
+
+// ScalarBaseMultConstantTime returns k*G where G is the base point of the
+// group and k is a big endian integer.  It computes the same result as
+// ScalarBaseMult, but its running time and memory access pattern do not
+// depend on k, so it is safe to use with secret scalars such as private keys
+// and nonces.
+func (curve *KoblitzCurve) ScalarBaseMultConstantTime(k []byte) (*big.Int, *big.Int) {
+	qx, qy, qz := new(fieldVal), new(fieldVal), new(fieldVal)
+	curve.scalarBaseMultConstantTimeJacobian(k, qx, qy, qz)
+	return curve.fieldJacobianToBigAffine(qx, qy, qz)
+}
+
+// scalarBaseMultConstantTimeJacobian computes k*G in constant time and stores
+// the result as a Jacobian point in (qx, qy, qz).
+func (curve *KoblitzCurve) scalarBaseMultConstantTimeJacobian(k []byte, qx, qy, qz *fieldVal) {
+	ctBaseTableOnce.Do(curve.buildCTBaseTable)
+
+	// Q = sum(table[w][digit_w]) over every window w.
+	kb := curve.ctScalarBytes(k)
+	qx.Zero()
+	qy.Zero()
+	qz.Zero()
+	var tx, ty, tz fieldVal
+	for w := 0; w < ctWindows; w++ {
+		ctLookup(&ctBaseTable[w], ctDigit(&kb, w), &tx, &ty, &tz)
+		curve.addConstantTime(qx, qy, qz, &tx, &ty, &tz, qx, qy, qz)
+	}
+}
+
+// buildCTBaseTable computes the window table used by
+// ScalarBaseMultConstantTime.
+func (curve *KoblitzCurve) buildCTBaseTable() {
+	var table [ctWindows][ctWindowSize][3]fieldVal
+
+	// base = 16^w*G for the current window w.
+	var bx, by, bz fieldVal
+	gx, gy := curve.bigAffineToField(curve.Gx, curve.Gy)
+	bx.Set(gx)
+	by.Set(gy)
+	bz.SetInt(1)
+	for w := 0; w < ctWindows; w++ {
+		window := &table[w]
+		window[1][0].Set(&bx)
+		window[1][1].Set(&by)
+		window[1][2].Set(&bz)
+		for i := 2; i < ctWindowSize; i++ {
+			prev, t := &window[i-1], &window[i]
+			curve.addConstantTime(&prev[0], &prev[1], &prev[2],
+				&bx, &by, &bz, &t[0], &t[1], &t[2])
+		}
+		for i := 0; i < ctWindowBits; i++ {
+			curve.doubleGeneric(&bx, &by, &bz, &bx, &by, &bz)
+		}
+	}
+	ctBaseTable = &table
+}
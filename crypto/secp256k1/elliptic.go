@@ -749,8 +749,22 @@ func NAF(k []byte) ([]byte, []byte) {
 // ScalarMult returns k*(Bx, By) where k is a big endian integer.
 // Part of the elliptic.Curve interface.
 func (curve *KoblitzCurve) ScalarMult(Bx, By *big.Int, k []byte) (*big.Int, *big.Int) {
-	// Point Q = ∞ (point at infinity).
 	qx, qy, qz := new(fieldVal), new(fieldVal), new(fieldVal)
+	px, py := curve.bigAffineToField(Bx, By)
+	curve.scalarMultJacobian(px, py, k, qx, qy, qz)
+
+	// Convert the Jacobian coordinate field values back to affine big.Ints.
+	return curve.fieldJacobianToBigAffine(qx, qy, qz)
+}
+
+// scalarMultJacobian computes k*(px, py) where (px, py) is an affine point and
+// k is a big endian integer, and stores the result as a Jacobian point in
+// (qx, qy, qz).
+func (curve *KoblitzCurve) scalarMultJacobian(px, py *fieldVal, k []byte, qx, qy, qz *fieldVal) {
+	// Point Q = ∞ (point at infinity).
+	qx.Zero()
+	qy.Zero()
+	qz.Zero()
 
 	// Decompose K into k1 and k2 in order to halve the number of EC ops.
 	// See Algorithm 3.74 in [GECC].
@@ -760,7 +774,7 @@ func (curve *KoblitzCurve) ScalarMult(Bx, By *big.Int, k []byte) (*big.Int, *big
 	//   k * P = k1 * P + k2 * ϕ(P)
 	//
 	// P1 below is P in the equation, P2 below is ϕ(P) in the equation
-	p1x, p1y := curve.bigAffineToField(Bx, By)
+	p1x, p1y := new(fieldVal).Set(px), new(fieldVal).Set(py)
 	p1yNeg := new(fieldVal).NegateVal(p1y, 1)
 	p1z := new(fieldVal).SetInt(1)
 
@@ -845,20 +859,29 @@ func (curve *KoblitzCurve) ScalarMult(Bx, By *big.Int, k []byte) (*big.Int, *big
 			k2ByteNeg <<= 1
 		}
 	}
-
-	// Convert the Jacobian coordinate field values back to affine big.Ints.
-	return curve.fieldJacobianToBigAffine(qx, qy, qz)
 }
 
 // ScalarBaseMult returns k*G where G is the base point of the group and k is a
 // big endian integer.
 // Part of the elliptic.Curve interface.
 func (curve *KoblitzCurve) ScalarBaseMult(k []byte) (*big.Int, *big.Int) {
+	qx, qy, qz := new(fieldVal), new(fieldVal), new(fieldVal)
+	curve.scalarBaseMultJacobian(k, qx, qy, qz)
+	return curve.fieldJacobianToBigAffine(qx, qy, qz)
+}
+
+// scalarBaseMultJacobian computes k*G where G is the base point of the group
+// and k is a big endian integer, and stores the result as a Jacobian point in
+// (qx, qy, qz).
+func (curve *KoblitzCurve) scalarBaseMultJacobian(k []byte, qx, qy, qz *fieldVal) {
+	bytePoints := curve.getBytePoints()
 	newK := curve.moduloReduce(k)
-	diff := len(curve.bytePoints) - len(newK)
+	diff := len(bytePoints) - len(newK)
 
 	// Point Q = ∞ (point at infinity).
-	qx, qy, qz := new(fieldVal), new(fieldVal), new(fieldVal)
+	qx.Zero()
+	qy.Zero()
+	qz.Zero()
 
 	// curve.bytePoints has all 256 byte points for each 8-bit window. The
 	// strategy is to add up the byte points. This is best understood by
@@ -866,10 +889,9 @@ func (curve *KoblitzCurve) ScalarBaseMult(k []byte) (*big.Int, *big.Int) {
 	// Each "digit" in the 8-bit window can be looked up using bytePoints
 	// and added together.
 	for i, byteVal := range newK {
-		p := curve.bytePoints[diff+i][byteVal]
+		p := bytePoints[diff+i][byteVal]
 		curve.addJacobian(qx, qy, qz, &p[0], &p[1], &p[2], qx, qy, qz)
 	}
-	return curve.fieldJacobianToBigAffine(qx, qy, qz)
 }
 
 // QPlus1Div4 returns the (P+1)/4 constant for the curve for use in calculating
@@ -0,0 +1,64 @@
+// This file is ignored during the regular build due to the following build tag.
+// It is called by go generate and used to automatically generate pre-computed
+// tables used to accelerate operations.
+
+//go:build ignore
+// +build ignore
+
+package main
+
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/base64"
+	"fmt"
+	"github.com/w3liu/go-common/crypto/secp256k1"
+	"io/ioutil"
+	"log"
+)
+
+func main() {
+	// The package is built with the gensecp256k1 tag, so the byte points
+	// are computed from the curve rather than read from secp256k1.go.
+	serialized := secp256k1.S256().SerializedBytePoints()
+
+	// Compress the serialized byte points.
+	var compressed bytes.Buffer
+	w := zlib.NewWriter(&compressed)
+	if _, err := w.Write(serialized); err != nil {
+		log.Fatal(err)
+	}
+	if err := w.Close(); err != nil {
+		log.Fatal(err)
+	}
+
+	// Encode the compressed byte points with base64.
+	encoded := base64.StdEncoding.EncodeToString(compressed.Bytes())
+
+	var buf bytes.Buffer
+	fmt.Fprintln(&buf, "// Copyright (c) 2015 The btcsuite developers")
+	fmt.Fprintln(&buf, "// Use of this source code is governed by an ISC")
+	fmt.Fprintln(&buf, "// license that can be found in the LICENSE file.")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "//go:build !gensecp256k1 && !lazysecp256k1")
+	fmt.Fprintln(&buf, "// +build !gensecp256k1,!lazysecp256k1")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "package secp256k1")
+	fmt.Fprintln(&buf)
+	fmt.Fprintln(&buf, "// Auto-generated file (see genprecomps.go)")
+	fmt.Fprintln(&buf, "// DO NOT EDIT")
+	fmt.Fprintln(&buf)
+	fmt.Fprintf(&buf, "var secp256k1BytePoints = %q\n", encoded)
+	if err := ioutil.WriteFile("secp256k1.go", buf.Bytes(), 0644); err != nil {
+		log.Fatal(err)
+	}
+
+	a1, b1, a2, b2 := secp256k1.S256().EndomorphismVectors()
+	fmt.Println("The following values are the computed linearly " +
+		"independent vectors needed to make use of the secp256k1 " +
+		"endomorphism:")
+	fmt.Printf("a1: %x\n", a1)
+	fmt.Printf("b1: %x\n", b1)
+	fmt.Printf("a2: %x\n", a2)
+	fmt.Printf("b2: %x\n", b2)
+}
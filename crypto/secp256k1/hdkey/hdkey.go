@@ -0,0 +1,422 @@
+package hdkey
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/w3liu/go-common/crypto/base58"
+	"github.com/w3liu/go-common/crypto/secp256k1"
+	"golang.org/x/crypto/ripemd160"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+const (
+	// HardenedKeyStart is the index at which a hardened key starts.  Each
+	// extended key has 2^31 normal child keys and 2^31 hardened child keys.
+	HardenedKeyStart = 0x80000000 // 2^31
+
+	// MinSeedBytes is the minimum number of bytes allowed for a seed to
+	// a master node.
+	MinSeedBytes = 16 // 128 bits
+
+	// MaxSeedBytes is the maximum number of bytes allowed for a seed to
+	// a master node.
+	MaxSeedBytes = 64 // 512 bits
+
+	// serializedKeyLen is the length of a serialized public or private
+	// extended key.  It consists of 4 bytes version, 1 byte depth, 4 bytes
+	// fingerprint, 4 bytes child number, 32 bytes chain code, and 33 bytes
+	// public/private key data.
+	serializedKeyLen = 4 + 1 + 4 + 4 + 32 + 33 // 78 bytes
+)
+
+var (
+	// ErrDeriveHardFromPublic is returned when trying to derive a hardened
+	// child from a public extended key.
+	ErrDeriveHardFromPublic = errors.New("cannot derive a hardened key from a public key")
+
+	// ErrNotPrivExtKey is returned when a private key is requested from a
+	// public extended key.
+	ErrNotPrivExtKey = errors.New("unable to create private keys from a public extended key")
+
+	// ErrInvalidChild is returned when a derived key is invalid, which
+	// happens with a probability lower than 1 in 2^127.  The next index
+	// should be used instead.
+	ErrInvalidChild = errors.New("the extended key at this index is invalid")
+
+	// ErrInvalidSeedLen is returned when the seed is not between
+	// MinSeedBytes and MaxSeedBytes.
+	ErrInvalidSeedLen = fmt.Errorf("seed length must be between %d and %d bytes",
+		MinSeedBytes, MaxSeedBytes)
+
+	// ErrInvalidKeyLen is returned when a serialized extended key has the
+	// wrong length.
+	ErrInvalidKeyLen = errors.New("the provided serialized extended key length is invalid")
+
+	// ErrInvalidPath is returned when a derivation path can't be parsed.
+	ErrInvalidPath = errors.New("invalid derivation path")
+)
+
+var (
+	// masterKey is the key used to generate the master node, as defined by
+	// BIP-32.
+	masterKey = []byte("Bitcoin seed")
+
+	// MainNetPrivateVersion is the version that serializes to "xprv".
+	MainNetPrivateVersion = [4]byte{0x04, 0x88, 0xad, 0xe4}
+
+	// MainNetPublicVersion is the version that serializes to "xpub".
+	MainNetPublicVersion = [4]byte{0x04, 0x88, 0xb2, 0x1e}
+)
+
+// ExtendedKey houses all the information needed to support a hierarchical
+// deterministic extended key.
+type ExtendedKey struct {
+	key       []byte // 32 bytes private key or 33 bytes compressed public key
+	chainCode []byte
+	parentFP  []byte
+	depth     uint8
+	childNum  uint32
+	isPrivate bool
+}
+
+// NewMaster creates a new master node for use in creating a hierarchical
+// deterministic key chain.  The seed must be between 128 and 512 bits and
+// should be generated by a cryptographically secure random generation source,
+// or derived from a mnemonic with NewSeed.
+func NewMaster(seed []byte) (*ExtendedKey, error) {
+	if len(seed) < MinSeedBytes || len(seed) > MaxSeedBytes {
+		return nil, ErrInvalidSeedLen
+	}
+
+	// First take the HMAC-SHA512 of the master key and the seed data:
+	//   I = HMAC-SHA512(Key = "Bitcoin seed", Data = S)
+	hmac512 := hmac.New(sha512.New, masterKey)
+	hmac512.Write(seed)
+	lr := hmac512.Sum(nil)
+
+	// Split "I" into two 32-byte sequences Il and Ir where:
+	//   Il = master secret key
+	//   Ir = master chain code
+	secretKey := lr[:len(lr)/2]
+	chainCode := lr[len(lr)/2:]
+
+	// Ensure the key is usable.
+	k := new(big.Int).SetBytes(secretKey)
+	if k.Sign() == 0 || k.Cmp(secp256k1.S256().N) >= 0 {
+		return nil, ErrInvalidChild
+	}
+
+	return &ExtendedKey{
+		key:       secretKey,
+		chainCode: chainCode,
+		parentFP:  []byte{0x00, 0x00, 0x00, 0x00},
+		isPrivate: true,
+	}, nil
+}
+
+// IsPrivate returns whether or not the extended key is a private extended key.
+func (k *ExtendedKey) IsPrivate() bool {
+	return k.isPrivate
+}
+
+// Depth returns the current derivation level with respect to the root.  The
+// root key has depth zero.
+func (k *ExtendedKey) Depth() uint8 {
+	return k.depth
+}
+
+// ChildIndex returns the index at which the child extended key was derived.
+func (k *ExtendedKey) ChildIndex() uint32 {
+	return k.childNum
+}
+
+// ParentFingerprint returns a fingerprint of the parent extended key from
+// which this one was derived.
+func (k *ExtendedKey) ParentFingerprint() uint32 {
+	return binary.BigEndian.Uint32(k.parentFP)
+}
+
+// pubKeyBytes returns bytes for the serialized compressed public key
+// associated with this extended key.
+func (k *ExtendedKey) pubKeyBytes() []byte {
+	if !k.isPrivate {
+		return k.key
+	}
+	priv, err := secp256k1.PrivKeyFromBytes(k.key)
+	if err != nil {
+		// Private extended keys are validated when they are created.
+		panic(err)
+	}
+	return priv.PubKey().SerializeCompressed()
+}
+
+// Derive returns a derived child extended key at the given index.
+//
+// When this extended key is a private extended key, a private extended key
+// is returned, otherwise a public extended key.  Indices starting at
+// HardenedKeyStart derive hardened children, which can only be derived from a
+// private extended key.  ErrInvalidChild is returned in the rare case that the
+// child at the index is invalid, and the next index should be used instead.
+func (k *ExtendedKey) Derive(i uint32) (*ExtendedKey, error) {
+	if k.depth == 255 {
+		return nil, errors.New("cannot derive a key with more than 255 indices in its path")
+	}
+
+	isChildHardened := i >= HardenedKeyStart
+	if !k.isPrivate && isChildHardened {
+		return nil, ErrDeriveHardFromPublic
+	}
+
+	// The data used to derive the child key depends on whether or not the
+	// child is hardened per [BIP32].
+	//
+	// For hardened children:
+	//   0x00 || ser256(parentKey) || ser32(i)
+	//
+	// For normal children:
+	//   serP(parentPubKey) || ser32(i)
+	keyLen := 33
+	data := make([]byte, keyLen+4)
+	if isChildHardened {
+		copy(data[keyLen-len(k.key):], k.key)
+	} else {
+		copy(data, k.pubKeyBytes())
+	}
+	binary.BigEndian.PutUint32(data[keyLen:], i)
+
+	// Take the HMAC-SHA512 of the current key's chain code and the derived
+	// data:
+	//   I = HMAC-SHA512(Key = chainCode, Data = data)
+	hmac512 := hmac.New(sha512.New, k.chainCode)
+	hmac512.Write(data)
+	ilr := hmac512.Sum(nil)
+
+	// Split "I" into two 32-byte sequences Il and Ir where:
+	//   Il = intermediate key used to derive the child
+	//   Ir = child chain code
+	il := ilr[:len(ilr)/2]
+	childChainCode := ilr[len(ilr)/2:]
+
+	// Both derived public or private keys rely on treating the left 32-byte
+	// sequence calculated above (Il) as a 256-bit integer that must be
+	// within the valid range for a secp256k1 private key.
+	curve := secp256k1.S256()
+	ilNum := new(big.Int).SetBytes(il)
+	if ilNum.Cmp(curve.N) >= 0 {
+		return nil, ErrInvalidChild
+	}
+
+	var childKey []byte
+	if k.isPrivate {
+		// childKey = parse256(Il) + parentKey mod n
+		keyNum := new(big.Int).SetBytes(k.key)
+		ilNum.Add(ilNum, keyNum)
+		ilNum.Mod(ilNum, curve.N)
+		if ilNum.Sign() == 0 {
+			return nil, ErrInvalidChild
+		}
+		childKey = make([]byte, 32)
+		b := ilNum.Bytes()
+		copy(childKey[32-len(b):], b)
+	} else {
+		// childKey = serP(point(parse256(Il)) + parentKey)
+		pubKey, err := secp256k1.ParsePubKey(k.key)
+		if err != nil {
+			return nil, err
+		}
+		ilx, ily := curve.ScalarBaseMult(il)
+		x, y := curve.Add(ilx, ily, pubKey.X, pubKey.Y)
+		if x.Sign() == 0 && y.Sign() == 0 {
+			return nil, ErrInvalidChild
+		}
+		childKey = (&secp256k1.PublicKey{Curve: curve, X: x, Y: y}).SerializeCompressed()
+	}
+
+	// The fingerprint of the parent for the derived child is the first 4
+	// bytes of the RIPEMD160(SHA256(parentPubKey)).
+	parentFP := hash160(k.pubKeyBytes())[:4]
+	return &ExtendedKey{
+		key:       childKey,
+		chainCode: childChainCode,
+		parentFP:  parentFP,
+		depth:     k.depth + 1,
+		childNum:  i,
+		isPrivate: k.isPrivate,
+	}, nil
+}
+
+// DerivePath derives the descendant extended key at a path such as
+// "m/44'/60'/0'/0/1", see ParsePath.  The path is relative to this key.
+func (k *ExtendedKey) DerivePath(path string) (*ExtendedKey, error) {
+	indices, err := ParsePath(path)
+	if err != nil {
+		return nil, err
+	}
+	key := k
+	for _, i := range indices {
+		if key, err = key.Derive(i); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// ParsePath parses a derivation path such as "m/44'/60'/0'/0/1" into child
+// indices.  Hardened indices are marked with a trailing ', h or H, and the
+// leading "m" is optional.
+func ParsePath(path string) ([]uint32, error) {
+	parts := strings.Split(strings.TrimSpace(path), "/")
+	if len(parts) > 0 && parts[0] == "m" {
+		parts = parts[1:]
+	}
+	indices := make([]uint32, 0, len(parts))
+	for _, part := range parts {
+		if part == "" {
+			return nil, ErrInvalidPath
+		}
+		var offset uint32
+		if last := part[len(part)-1]; last == '\'' || last == 'h' || last == 'H' {
+			offset = HardenedKeyStart
+			part = part[:len(part)-1]
+		}
+		i, err := strconv.ParseUint(part, 10, 32)
+		if err != nil || uint32(i) >= HardenedKeyStart {
+			return nil, ErrInvalidPath
+		}
+		indices = append(indices, uint32(i)+offset)
+	}
+	return indices, nil
+}
+
+// Neuter returns a new extended public key from this extended private key.
+// The same extended key is returned if it is already a public key.
+func (k *ExtendedKey) Neuter() *ExtendedKey {
+	if !k.isPrivate {
+		return k
+	}
+	return &ExtendedKey{
+		key:       k.pubKeyBytes(),
+		chainCode: k.chainCode,
+		parentFP:  k.parentFP,
+		depth:     k.depth,
+		childNum:  k.childNum,
+		isPrivate: false,
+	}
+}
+
+// ECPubKey returns the secp256k1 public key of the extended key.
+func (k *ExtendedKey) ECPubKey() (*secp256k1.PublicKey, error) {
+	return secp256k1.ParsePubKey(k.pubKeyBytes())
+}
+
+// ECPrivKey returns the secp256k1 private key of the extended key.
+// ErrNotPrivExtKey is returned for a public extended key.
+func (k *ExtendedKey) ECPrivKey() (*secp256k1.PrivateKey, error) {
+	if !k.isPrivate {
+		return nil, ErrNotPrivExtKey
+	}
+	return secp256k1.PrivKeyFromBytes(k.key)
+}
+
+// String returns the extended key as a base58check encoded string, which
+// starts with "xprv" for private keys and "xpub" for public keys.
+func (k *ExtendedKey) String() string {
+	version := MainNetPublicVersion
+	if k.isPrivate {
+		version = MainNetPrivateVersion
+	}
+
+	var childNumBytes [4]byte
+	binary.BigEndian.PutUint32(childNumBytes[:], k.childNum)
+
+	// The serialized format is:
+	//   version (4) || depth (1) || parent fingerprint (4)) ||
+	//   child num (4) || chain code (32) || key data (33)
+	serializedBytes := make([]byte, 0, serializedKeyLen)
+	serializedBytes = append(serializedBytes, version[:]...)
+	serializedBytes = append(serializedBytes, k.depth)
+	serializedBytes = append(serializedBytes, k.parentFP...)
+	serializedBytes = append(serializedBytes, childNumBytes[:]...)
+	serializedBytes = append(serializedBytes, k.chainCode...)
+	if k.isPrivate {
+		serializedBytes = append(serializedBytes, 0x00)
+		serializedBytes = append(serializedBytes, k.key...)
+	} else {
+		serializedBytes = append(serializedBytes, k.key...)
+	}
+	return base58.CheckEncode(serializedBytes)
+}
+
+// NewKeyFromString returns a new extended key instance from a base58check
+// encoded xprv or xpub string.
+func NewKeyFromString(key string) (*ExtendedKey, error) {
+	payload, err := base58.CheckDecode(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(payload) != serializedKeyLen {
+		return nil, ErrInvalidKeyLen
+	}
+
+	// Deserialize each of the payload fields.
+	version := payload[:4]
+	depth := payload[4:5][0]
+	parentFP := payload[5:9]
+	childNum := binary.BigEndian.Uint32(payload[9:13])
+	chainCode := payload[13:45]
+	keyData := payload[45:78]
+
+	var isPrivate bool
+	switch {
+	case bytes.Equal(version, MainNetPrivateVersion[:]):
+		isPrivate = true
+	case bytes.Equal(version, MainNetPublicVersion[:]):
+	default:
+		return nil, errors.New("unknown extended key version")
+	}
+
+	if isPrivate {
+		// Ensure the private key is valid.  It must be within the range
+		// of the order of the secp256k1 curve and not be 0.
+		if keyData[0] != 0x00 {
+			return nil, errors.New("invalid private extended key data")
+		}
+		keyData = keyData[1:]
+		if _, err := secp256k1.PrivKeyFromBytes(keyData); err != nil {
+			return nil, err
+		}
+	} else {
+		// Ensure the public key parses correctly and is actually on the
+		// secp256k1 curve.
+		if !secp256k1.IsCompressedPubKey(keyData) {
+			return nil, errors.New("invalid public extended key data")
+		}
+		if _, err := secp256k1.ParsePubKey(keyData); err != nil {
+			return nil, err
+		}
+	}
+
+	return &ExtendedKey{
+		key:       append([]byte(nil), keyData...),
+		chainCode: append([]byte(nil), chainCode...),
+		parentFP:  append([]byte(nil), parentFP...),
+		depth:     depth,
+		childNum:  childNum,
+		isPrivate: isPrivate,
+	}, nil
+}
+
+// hash160 returns RIPEMD160(SHA256(b)).
+func hash160(b []byte) []byte {
+	h := sha256.Sum256(b)
+	r := ripemd160.New()
+	r.Write(h[:])
+	return r.Sum(nil)
+}
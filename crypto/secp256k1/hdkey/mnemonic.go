@@ -0,0 +1,127 @@
+package hdkey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+	"strings"
+)
+
+var (
+	// ErrInvalidEntropyLen is returned when the entropy is not a multiple of
+	// 32 bits between 128 and 256 bits.
+	ErrInvalidEntropyLen = errors.New("entropy length must be a multiple of 32 bits between 128 and 256 bits")
+
+	// ErrInvalidMnemonic is returned when a mnemonic has an unknown word,
+	// a wrong number of words or a bad checksum.
+	ErrInvalidMnemonic = errors.New("invalid mnemonic")
+)
+
+// wordIndex maps each word of the English word list to its index.
+var wordIndex = func() map[string]int {
+	m := make(map[string]int, len(englishWordList))
+	for i, w := range englishWordList {
+		m[w] = i
+	}
+	return m
+}()
+
+// NewEntropy returns bitSize bits of random entropy for NewMnemonic.  The
+// bitSize must be a multiple of 32 between 128 and 256.
+func NewEntropy(bitSize int) ([]byte, error) {
+	if bitSize%32 != 0 || bitSize < 128 || bitSize > 256 {
+		return nil, ErrInvalidEntropyLen
+	}
+	entropy := make([]byte, bitSize/8)
+	if _, err := rand.Read(entropy); err != nil {
+		return nil, err
+	}
+	return entropy, nil
+}
+
+// NewMnemonic returns the BIP-39 English mnemonic sentence for the entropy.
+// The sentence has 3 words for every 32 bits of entropy.
+func NewMnemonic(entropy []byte) (string, error) {
+	bitSize := len(entropy) * 8
+	if bitSize%32 != 0 || bitSize < 128 || bitSize > 256 {
+		return "", ErrInvalidEntropyLen
+	}
+
+	// The checksum is the first ENT/32 bits of SHA256(entropy) and is
+	// appended to the entropy.  Every 11 bits then select one word.
+	checksum := sha256.Sum256(entropy)
+	bits := append(append([]byte(nil), entropy...), checksum[0])
+	words := make([]string, (bitSize+bitSize/32)/11)
+	for i := range words {
+		words[i] = englishWordList[readBits(bits, i*11, 11)]
+	}
+	return strings.Join(words, " "), nil
+}
+
+// EntropyFromMnemonic returns the entropy encoded by a BIP-39 English mnemonic
+// sentence, verifying its checksum.
+func EntropyFromMnemonic(mnemonic string) ([]byte, error) {
+	words := strings.Fields(mnemonic)
+	if len(words)%3 != 0 || len(words) < 12 || len(words) > 24 {
+		return nil, ErrInvalidMnemonic
+	}
+
+	totalBits := len(words) * 11
+	checksumBits := totalBits / 33
+	bits := make([]byte, (totalBits+7)/8)
+	for i, w := range words {
+		idx, ok := wordIndex[w]
+		if !ok {
+			return nil, ErrInvalidMnemonic
+		}
+		writeBits(bits, i*11, 11, idx)
+	}
+
+	entropy := bits[:(totalBits-checksumBits)/8]
+	checksum := sha256.Sum256(entropy)
+	if readBits(bits, totalBits-checksumBits, checksumBits) !=
+		readBits(checksum[:], 0, checksumBits) {
+		return nil, ErrInvalidMnemonic
+	}
+	return entropy, nil
+}
+
+// IsMnemonicValid reports whether the mnemonic is a valid BIP-39 English
+// mnemonic sentence.
+func IsMnemonicValid(mnemonic string) bool {
+	_, err := EntropyFromMnemonic(mnemonic)
+	return err == nil
+}
+
+// NewSeed returns the 64-byte BIP-39 seed for the mnemonic and passphrase,
+// which can be passed to NewMaster.  The mnemonic is not validated, use
+// IsMnemonicValid for that.
+func NewSeed(mnemonic, passphrase string) []byte {
+	password := norm.NFKD.String(strings.Join(strings.Fields(mnemonic), " "))
+	salt := norm.NFKD.String("mnemonic" + passphrase)
+	return pbkdf2.Key([]byte(password), []byte(salt), 2048, 64, sha512.New)
+}
+
+// readBits returns n bits of b starting at bit offset off, most significant
+// bit first.
+func readBits(b []byte, off, n int) int {
+	v := 0
+	for i := off; i < off+n; i++ {
+		v = v<<1 | int(b[i/8]>>(7-uint(i%8))&1)
+	}
+	return v
+}
+
+// writeBits writes the low n bits of v into b starting at bit offset off,
+// most significant bit first.
+func writeBits(b []byte, off, n, v int) {
+	for i := 0; i < n; i++ {
+		if v>>(uint(n-1-i))&1 == 1 {
+			pos := off + i
+			b[pos/8] |= 1 << (7 - uint(pos%8))
+		}
+	}
+}
@@ -4,12 +4,39 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"io"
+	"math/big"
 )
 
 func NewPrivateKey(curve elliptic.Curve) (*ecdsa.PrivateKey, error) {
+	// The public key of a secp256k1 key is computed in constant time, since
+	// ecdsa.GenerateKey would use the variable time ScalarBaseMult.
+	if koblitz, ok := curve.(*KoblitzCurve); ok {
+		return newKoblitzPrivateKey(koblitz)
+	}
 	key, err := ecdsa.GenerateKey(curve, rand.Reader)
 	if err != nil {
 		return nil, err
 	}
 	return key, nil
 }
+
+// newKoblitzPrivateKey generates a random private key in [1, N-1] the same way
+// ecdsa.GenerateKey does, reading 64 extra bits to make the bias from the
+// modular reduction negligible.
+func newKoblitzPrivateKey(curve *KoblitzCurve) (*ecdsa.PrivateKey, error) {
+	b := make([]byte, curve.byteSize+8)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return nil, err
+	}
+	d := new(big.Int).SetBytes(b)
+	n := new(big.Int).Sub(curve.N, one)
+	d.Mod(d, n)
+	d.Add(d, one)
+
+	x, y := curve.ScalarBaseMultConstantTime(d.Bytes())
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{Curve: curve, X: x, Y: y},
+		D:         d,
+	}, nil
+}
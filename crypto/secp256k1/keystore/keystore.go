@@ -0,0 +1,342 @@
+package keystore
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/w3liu/go-common/crypto/secp256k1"
+	"github.com/w3liu/go-common/crypto/secp256k1/address"
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/crypto/scrypt"
+	"golang.org/x/crypto/sha3"
+	"io"
+	"math"
+	"strings"
+)
+
+const (
+	// Version is the Web3 Secret Storage version written and read by this
+	// package.
+	Version = 3
+
+	// KDFScrypt is the scrypt key derivation function.
+	KDFScrypt = "scrypt"
+
+	// KDFPBKDF2 is the PBKDF2 key derivation function with HMAC-SHA256.
+	KDFPBKDF2 = "pbkdf2"
+
+	cipherAES128CTR = "aes-128-ctr"
+	prfHMACSHA256   = "hmac-sha256"
+	derivedKeyLen   = 32
+	saltLen         = 32
+)
+
+var (
+	// ErrDecrypt is returned when the MAC of a keystore file does not match,
+	// which almost always means the passphrase is wrong.
+	ErrDecrypt = errors.New("could not decrypt key with given passphrase")
+
+	// ErrUnsupported is returned when a keystore file uses a version,
+	// cipher or key derivation function this package does not support.
+	ErrUnsupported = errors.New("unsupported keystore format")
+)
+
+// Options selects the key derivation function used to encrypt a key and its
+// cost.
+type Options struct {
+	// KDF is KDFScrypt or KDFPBKDF2.
+	KDF string
+
+	// ScryptN and ScryptP are the scrypt CPU/memory cost and
+	// parallelization parameters.  The block size r is always 8.
+	ScryptN int
+	ScryptP int
+
+	// PBKDF2Iterations is the PBKDF2 iteration count.
+	PBKDF2Iterations int
+}
+
+var (
+	// StandardScrypt is the scrypt cost used by Ethereum clients, which
+	// takes about a second and 256MB of memory.
+	StandardScrypt = Options{KDF: KDFScrypt, ScryptN: 1 << 18, ScryptP: 1}
+
+	// LightScrypt is a scrypt cost for devices with little memory, which
+	// takes about 100ms and 4MB of memory.
+	LightScrypt = Options{KDF: KDFScrypt, ScryptN: 1 << 12, ScryptP: 6}
+
+	// StandardPBKDF2 is the PBKDF2 cost used by the Web3 Secret Storage
+	// test vectors.
+	StandardPBKDF2 = Options{KDF: KDFPBKDF2, PBKDF2Iterations: 262144}
+)
+
+// scryptR is the scrypt block size.
+const scryptR = 8
+
+// Upper bounds on the key derivation parameters read from keystore files,
+// so that a crafted file cannot make Import exhaust memory or CPU.  They
+// leave room for at least four times the StandardScrypt and StandardPBKDF2 costs.
+const (
+	maxScryptN      = 1 << 20
+	maxScryptMem    = 1 << 23 // n*r, 128*n*r bytes = 1GB
+	maxScryptWork   = 1 << 24 // n*r*p
+	maxPBKDF2Rounds = 1 << 20
+)
+
+// encryptedKeyJSON is the Web3 Secret Storage v3 JSON format.
+type encryptedKeyJSON struct {
+	Address string     `json:"address,omitempty"`
+	Crypto  cryptoJSON `json:"crypto"`
+	ID      string     `json:"id"`
+	Version int        `json:"version"`
+}
+
+type cryptoJSON struct {
+	Cipher       string                 `json:"cipher"`
+	CipherText   string                 `json:"ciphertext"`
+	CipherParams cipherParamsJSON       `json:"cipherparams"`
+	KDF          string                 `json:"kdf"`
+	KDFParams    map[string]interface{} `json:"kdfparams"`
+	MAC          string                 `json:"mac"`
+}
+
+type cipherParamsJSON struct {
+	IV string `json:"iv"`
+}
+
+// Export encrypts the private key with the passphrase and returns it in the
+// Web3 Secret Storage v3 JSON format, which Ethereum wallets can import.
+func Export(key *secp256k1.PrivateKey, passphrase string, opts Options) ([]byte, error) {
+	id, err := newUUID()
+	if err != nil {
+		return nil, err
+	}
+	return export(key, passphrase, opts, id)
+}
+
+func export(key *secp256k1.PrivateKey, passphrase string, opts Options, id string) ([]byte, error) {
+	salt := make([]byte, saltLen)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+	kdfParams := map[string]interface{}{
+		"dklen": derivedKeyLen,
+		"salt":  hex.EncodeToString(salt),
+	}
+	switch opts.KDF {
+	case KDFScrypt:
+		kdfParams["n"] = opts.ScryptN
+		kdfParams["r"] = scryptR
+		kdfParams["p"] = opts.ScryptP
+	case KDFPBKDF2:
+		kdfParams["c"] = opts.PBKDF2Iterations
+		kdfParams["prf"] = prfHMACSHA256
+	default:
+		return nil, fmt.Errorf("unknown kdf %q", opts.KDF)
+	}
+	derivedKey, err := deriveKey(opts.KDF, kdfParams, passphrase)
+	if err != nil {
+		return nil, err
+	}
+
+	iv := make([]byte, aes.BlockSize)
+	if _, err := io.ReadFull(rand.Reader, iv); err != nil {
+		return nil, err
+	}
+	cipherText, err := aesCTRXOR(derivedKey[:16], key.Serialize(), iv)
+	if err != nil {
+		return nil, err
+	}
+
+	addr := address.EthereumAddress(key.PubKey())
+	return json.Marshal(encryptedKeyJSON{
+		Address: strings.ToLower(addr[2:]),
+		Crypto: cryptoJSON{
+			Cipher:       cipherAES128CTR,
+			CipherText:   hex.EncodeToString(cipherText),
+			CipherParams: cipherParamsJSON{IV: hex.EncodeToString(iv)},
+			KDF:          opts.KDF,
+			KDFParams:    kdfParams,
+			MAC:          hex.EncodeToString(keystoreMAC(derivedKey, cipherText)),
+		},
+		ID:      id,
+		Version: Version,
+	})
+}
+
+// Import decrypts a private key in the Web3 Secret Storage v3 JSON format
+// with the passphrase.
+func Import(keyJSON []byte, passphrase string) (*secp256k1.PrivateKey, error) {
+	key, _, err := decrypt(keyJSON, passphrase)
+	return key, err
+}
+
+// ChangePassphrase re-encrypts a key in the Web3 Secret Storage v3 JSON
+// format with a new passphrase, keeping its id.
+func ChangePassphrase(keyJSON []byte, oldPassphrase, newPassphrase string, opts Options) ([]byte, error) {
+	key, k, err := decrypt(keyJSON, oldPassphrase)
+	if err != nil {
+		return nil, err
+	}
+	id := k.ID
+	if id == "" {
+		if id, err = newUUID(); err != nil {
+			return nil, err
+		}
+	}
+	return export(key, newPassphrase, opts, id)
+}
+
+func decrypt(keyJSON []byte, passphrase string) (*secp256k1.PrivateKey, *encryptedKeyJSON, error) {
+	k := new(encryptedKeyJSON)
+	if err := json.Unmarshal(keyJSON, k); err != nil {
+		return nil, nil, err
+	}
+	if k.Version != Version || k.Crypto.Cipher != cipherAES128CTR {
+		return nil, nil, ErrUnsupported
+	}
+
+	mac, err := hex.DecodeString(k.Crypto.MAC)
+	if err != nil {
+		return nil, nil, err
+	}
+	iv, err := hex.DecodeString(k.Crypto.CipherParams.IV)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(iv) != aes.BlockSize {
+		return nil, nil, errors.New("invalid iv length")
+	}
+	cipherText, err := hex.DecodeString(k.Crypto.CipherText)
+	if err != nil {
+		return nil, nil, err
+	}
+	derivedKey, err := deriveKey(k.Crypto.KDF, k.Crypto.KDFParams, passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	if subtle.ConstantTimeCompare(keystoreMAC(derivedKey, cipherText), mac) != 1 {
+		return nil, nil, ErrDecrypt
+	}
+
+	plainText, err := aesCTRXOR(derivedKey[:16], cipherText, iv)
+	if err != nil {
+		return nil, nil, err
+	}
+	// Some encoders strip the leading zero bytes of the key.
+	if len(plainText) < secp256k1.PrivKeyBytesLen {
+		padding := make([]byte, secp256k1.PrivKeyBytesLen-len(plainText))
+		plainText = append(padding, plainText...)
+	}
+	key, err := secp256k1.PrivKeyFromBytes(plainText)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key, k, nil
+}
+
+// deriveKey derives the encryption and MAC key from the passphrase with the
+// key derivation function and parameters of a keystore file.
+func deriveKey(kdf string, params map[string]interface{}, passphrase string) ([]byte, error) {
+	salt, err := hex.DecodeString(stringParam(params, "salt"))
+	if err != nil {
+		return nil, err
+	}
+	if intParam(params, "dklen") != derivedKeyLen {
+		return nil, errors.New("invalid dklen")
+	}
+
+	switch kdf {
+	case KDFScrypt:
+		n := intParam(params, "n")
+		r := intParam(params, "r")
+		p := intParam(params, "p")
+		if err := checkScryptParams(n, r, p); err != nil {
+			return nil, err
+		}
+		return scrypt.Key([]byte(passphrase), salt, n, r, p, derivedKeyLen)
+	case KDFPBKDF2:
+		if prf := stringParam(params, "prf"); prf != prfHMACSHA256 {
+			return nil, ErrUnsupported
+		}
+		c := intParam(params, "c")
+		if c <= 0 || c > maxPBKDF2Rounds {
+			return nil, errors.New("invalid pbkdf2 iteration count")
+		}
+		return pbkdf2.Key([]byte(passphrase), salt, c, derivedKeyLen, sha256.New), nil
+	}
+	return nil, ErrUnsupported
+}
+
+// checkScryptParams rejects scrypt parameters outside the bounds above.
+func checkScryptParams(n, r, p int) error {
+	if n <= 1 || n > maxScryptN || n&(n-1) != 0 {
+		return errors.New("invalid scrypt n")
+	}
+	if r <= 0 || p <= 0 || int64(r)*int64(p) >= 1<<30 {
+		return errors.New("invalid scrypt r or p")
+	}
+	if int64(n)*int64(r) > maxScryptMem || int64(n)*int64(r)*int64(p) > maxScryptWork {
+		return errors.New("scrypt parameters exceed cost limit")
+	}
+	return nil
+}
+
+// keystoreMAC returns Keccak-256(derivedKey[16:32] || cipherText).
+func keystoreMAC(derivedKey, cipherText []byte) []byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(derivedKey[16:32])
+	h.Write(cipherText)
+	return h.Sum(nil)
+}
+
+func aesCTRXOR(key, in, iv []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	out := make([]byte, len(in))
+	cipher.NewCTR(block, iv).XORKeyStream(out, in)
+	return out, nil
+}
+
+// intParam returns an integer KDF parameter, which encoding/json decodes as a
+// float64, 0 when it is missing or -1 when it is not a non-negative integer
+// that fits in 32 bits.
+func intParam(params map[string]interface{}, name string) int {
+	switch v := params[name].(type) {
+	case float64:
+		// Values that do not fit are reported as invalid rather than
+		// truncated.
+		if v != math.Trunc(v) || v < 0 || v > math.MaxInt32 {
+			return -1
+		}
+		return int(v)
+	case int:
+		return v
+	}
+	return 0
+}
+
+// stringParam returns a string KDF parameter or "" when it is missing.
+func stringParam(params map[string]interface{}, name string) string {
+	s, _ := params[name].(string)
+	return s
+}
+
+// newUUID returns a random version 4 UUID.
+func newUUID() (string, error) {
+	var u [16]byte
+	if _, err := io.ReadFull(rand.Reader, u[:]); err != nil {
+		return "", err
+	}
+	u[6] = u[6]&0x0f | 0x40
+	u[8] = u[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:]), nil
+}
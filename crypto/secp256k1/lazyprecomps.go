@@ -0,0 +1,9 @@
+//go:build gensecp256k1 || lazysecp256k1
+// +build gensecp256k1 lazysecp256k1
+
+package secp256k1
+
+// secp256k1BytePoints is empty when generating the pre-computed table or when
+// building with the lazysecp256k1 tag, so the byte points are computed from
+// the curve on first use instead of being compiled in.
+var secp256k1BytePoints = ""
@@ -0,0 +1,367 @@
+package secp256k1
+
+import (
+	"math/big"
+	"math/bits"
+	"sync"
+)
+
+// The functions in this file compute sums of scalar multiplications such as
+// u1*G + u2*Q with a single shared chain of point doublings, which is much
+// faster than computing every product separately and adding the results.
+// They are variable time and must only be used with public scalars, as is
+// the case for signature verification.
+//
+// Every scalar is first split with the endomorphism (see splitK) into two
+// half-length scalars, so k*P becomes k1*P + k2*ϕ(P) and the number of
+// doublings is halved.
+
+const (
+	// baseWNAFWidth is the window width used for the generator, whose
+	// odd multiples are precomputed once.
+	baseWNAFWidth = 8
+
+	// pointWNAFWidth is the window width used for other points, whose
+	// odd multiples have to be computed on every call.
+	pointWNAFWidth = 5
+
+	// pippengerThreshold is the number of points from which
+	// MultiScalarMult uses Pippenger's bucket method rather than Strauss'
+	// interleaved windows.
+	pippengerThreshold = 32
+)
+
+// baseOddMultiples holds the odd multiples G, 3G, 5G, ... and their images
+// under the endomorphism in affine form.  They are built on first use.
+var (
+	baseOddMultiples     [][3]fieldVal
+	basePhiOddMultiples  [][3]fieldVal
+	baseOddMultiplesOnce sync.Once
+)
+
+// straussTerm is one k*P term of a Strauss multiplication, with the odd
+// multiples of P and the width-w NAF of k, least significant digit first.
+type straussTerm struct {
+	table [][3]fieldVal
+	naf   []int8
+}
+
+// wNAF returns the width-w non-adjacent form of the big endian integer k,
+// least significant digit first, with every digit negated when sign is -1.
+// Every non-zero digit is odd and less than 2^(w-1) in absolute value, so w
+// must not exceed 8.
+func wNAF(k []byte, w uint, sign int) []int8 {
+	n := new(big.Int).SetBytes(k)
+	mod := int64(1) << w
+	digits := make([]int8, 0, len(k)*8+1)
+	d := new(big.Int)
+	for n.Sign() > 0 {
+		var digit int64
+		if n.Bit(0) == 1 {
+			digit = int64(n.Uint64() & uint64(mod-1))
+			if digit >= mod/2 {
+				digit -= mod
+			}
+			n.Sub(n, d.SetInt64(digit))
+		}
+		if sign < 0 {
+			digit = -digit
+		}
+		digits = append(digits, int8(digit))
+		n.Rsh(n, 1)
+	}
+	return digits
+}
+
+// oddMultiples returns P, 3P, 5P, ..., (2^(w-1)-1)P for the affine point
+// (px, py) in affine form, so adding them only needs the faster mixed
+// addition.
+func (curve *KoblitzCurve) oddMultiples(px, py *fieldVal, w uint) [][3]fieldVal {
+	table := make([][3]fieldVal, 1<<(w-2))
+	table[0][0].Set(px)
+	table[0][1].Set(py)
+	table[0][2].SetInt(1)
+
+	var dx, dy, dz fieldVal
+	curve.doubleJacobian(px, py, &table[0][2], &dx, &dy, &dz)
+	for i := 1; i < len(table); i++ {
+		prev, p := table[i-1], &table[i]
+		curve.addJacobian(&prev[0], &prev[1], &prev[2], &dx, &dy, &dz,
+			&p[0], &p[1], &p[2])
+	}
+	toAffine(table)
+	return table
+}
+
+// toAffine converts the Jacobian points of the table to affine form in place.
+// All the z values are inverted together with a single field inversion by
+// Montgomery's trick.  None of the points may be the point at infinity.
+func toAffine(table [][3]fieldVal) {
+	// prefix[i] = z0*z1*...*zi
+	prefix := make([]fieldVal, len(table))
+	prefix[0].Set(&table[0][2])
+	for i := 1; i < len(table); i++ {
+		prefix[i].Mul2(&prefix[i-1], &table[i][2])
+	}
+
+	var inv, zInv, zInv2 fieldVal
+	inv.Set(&prefix[len(table)-1]).Inverse()
+	for i := len(table) - 1; i >= 0; i-- {
+		// zInv = (z0*...*zi)^-1 * (z0*...*zi-1) = zi^-1
+		zInv.Set(&inv)
+		if i > 0 {
+			zInv.Mul(&prefix[i-1])
+			inv.Mul(&table[i][2])
+		}
+		p := &table[i]
+		zInv2.SquareVal(&zInv)
+		p[0].Mul(&zInv2).Normalize()           // X = X/Z^2
+		p[1].Mul(zInv2.Mul(&zInv)).Normalize() // Y = Y/Z^3
+		p[2].SetInt(1)
+	}
+}
+
+// phiMultiples returns the image of every point of the table under the
+// endomorphism ϕ(x, y) = (βx, y).  In Jacobian coordinates this is
+// ϕ(X, Y, Z) = (βX, Y, Z).
+func (curve *KoblitzCurve) phiMultiples(table [][3]fieldVal) [][3]fieldVal {
+	phi := make([][3]fieldVal, len(table))
+	for i := range table {
+		phi[i][0].Mul2(&table[i][0], curve.beta).Normalize()
+		phi[i][1].Set(&table[i][1])
+		phi[i][2].Set(&table[i][2])
+	}
+	return phi
+}
+
+// buildBaseOddMultiples computes the odd multiples of the generator used for
+// u1*G in CombinedMult.
+func (curve *KoblitzCurve) buildBaseOddMultiples() {
+	gx, gy := curve.bigAffineToField(curve.Gx, curve.Gy)
+	table := curve.oddMultiples(gx, gy, baseWNAFWidth)
+	baseOddMultiples = table
+	basePhiOddMultiples = curve.phiMultiples(table)
+}
+
+// appendGLVTerms splits k into k1 + k2*lambda and appends the Strauss terms
+// k1*P and k2*ϕ(P) for the odd multiples table of P and its image under ϕ.
+func (curve *KoblitzCurve) appendGLVTerms(terms []straussTerm, table, phiTable [][3]fieldVal, k []byte, w uint) []straussTerm {
+	k1, k2, signK1, signK2 := curve.splitK(curve.moduloReduce(k))
+	return append(terms,
+		straussTerm{table: table, naf: wNAF(k1, w, signK1)},
+		straussTerm{table: phiTable, naf: wNAF(k2, w, signK2)})
+}
+
+// strauss computes the sum of the terms with interleaved windows, sharing the
+// doublings between all of them, and stores the result as a Jacobian point in
+// (qx, qy, qz).
+func (curve *KoblitzCurve) strauss(terms []straussTerm, qx, qy, qz *fieldVal) {
+	qx.Zero()
+	qy.Zero()
+	qz.Zero()
+
+	m := 0
+	for _, t := range terms {
+		if len(t.naf) > m {
+			m = len(t.naf)
+		}
+	}
+
+	// The table entries are copied before use since the add routines
+	// normalize their inputs in place and the generator tables are shared
+	// between goroutines.
+	var px, py, pz fieldVal
+	for i := m - 1; i >= 0; i-- {
+		curve.doubleJacobian(qx, qy, qz, qx, qy, qz)
+		for _, t := range terms {
+			if i >= len(t.naf) || t.naf[i] == 0 {
+				continue
+			}
+			digit := t.naf[i]
+			if digit > 0 {
+				p := &t.table[digit/2]
+				px.Set(&p[0])
+				py.Set(&p[1])
+				pz.Set(&p[2])
+			} else {
+				p := &t.table[-digit/2]
+				px.Set(&p[0])
+				py.NegateVal(&p[1], 1).Normalize()
+				pz.Set(&p[2])
+			}
+			curve.addJacobian(qx, qy, qz, &px, &py, &pz, qx, qy, qz)
+		}
+	}
+}
+
+// CombinedMult returns u1*G + u2*(Px, Py) where u1 and u2 are big endian
+// integers.  This is the core of ECDSA and Schnorr verification and is
+// computed with a single chain of doublings (Shamir's trick, generalized by
+// Strauss), which is considerably faster than ScalarBaseMult and ScalarMult
+// followed by Add.
+func (curve *KoblitzCurve) CombinedMult(Px, Py *big.Int, u1, u2 []byte) (*big.Int, *big.Int) {
+	qx, qy, qz := new(fieldVal), new(fieldVal), new(fieldVal)
+	curve.combinedMultJacobian(Px, Py, u1, u2, qx, qy, qz)
+	return curve.fieldJacobianToBigAffine(qx, qy, qz)
+}
+
+// combinedMultJacobian computes u1*G + u2*(Px, Py) and stores the result as a
+// Jacobian point in (qx, qy, qz).
+func (curve *KoblitzCurve) combinedMultJacobian(Px, Py *big.Int, u1, u2 []byte, qx, qy, qz *fieldVal) {
+	baseOddMultiplesOnce.Do(curve.buildBaseOddMultiples)
+
+	px, py := curve.bigAffineToField(Px, Py)
+	table := curve.oddMultiples(px, py, pointWNAFWidth)
+	terms := make([]straussTerm, 0, 4)
+	terms = curve.appendGLVTerms(terms, baseOddMultiples, basePhiOddMultiples,
+		u1, baseWNAFWidth)
+	terms = curve.appendGLVTerms(terms, table, curve.phiMultiples(table),
+		u2, pointWNAFWidth)
+	curve.strauss(terms, qx, qy, qz)
+}
+
+// MultiScalarMult returns the sum of scalars[i]*(xs[i], ys[i]) where the
+// scalars are big endian integers.  Small inputs use Strauss' interleaved
+// windows and larger ones Pippenger's bucket method, whose cost per point
+// decreases as the number of points grows.  It panics if the slices do not
+// have the same length.
+func (curve *KoblitzCurve) MultiScalarMult(xs, ys []*big.Int, scalars [][]byte) (*big.Int, *big.Int) {
+	qx, qy, qz := new(fieldVal), new(fieldVal), new(fieldVal)
+	curve.multiScalarMultJacobian(xs, ys, scalars, qx, qy, qz)
+	return curve.fieldJacobianToBigAffine(qx, qy, qz)
+}
+
+// multiScalarMultJacobian computes the sum of scalars[i]*(xs[i], ys[i]) and
+// stores the result as a Jacobian point in (qx, qy, qz).
+func (curve *KoblitzCurve) multiScalarMultJacobian(xs, ys []*big.Int, scalars [][]byte, qx, qy, qz *fieldVal) {
+	if len(xs) != len(ys) || len(xs) != len(scalars) {
+		panic("secp256k1: mismatched number of points and scalars")
+	}
+	if len(xs) < pippengerThreshold {
+		terms := make([]straussTerm, 0, 2*len(xs))
+		for i := range xs {
+			px, py := curve.bigAffineToField(xs[i], ys[i])
+			table := curve.oddMultiples(px, py, pointWNAFWidth)
+			terms = curve.appendGLVTerms(terms, table, curve.phiMultiples(table),
+				scalars[i], pointWNAFWidth)
+		}
+		curve.strauss(terms, qx, qy, qz)
+		return
+	}
+	curve.pippenger(xs, ys, scalars, qx, qy, qz)
+}
+
+// pippenger computes the sum of scalars[i]*(xs[i], ys[i]) with Pippenger's
+// bucket method and stores the result as a Jacobian point in (qx, qy, qz).
+//
+// The scalars are processed c bits at a time from the most significant
+// window.  In every window each point is added to the bucket selected by its
+// c-bit digit, and the buckets are then summed so that bucket j counts j
+// times, with two additions per bucket through a running sum.
+func (curve *KoblitzCurve) pippenger(xs, ys []*big.Int, scalars [][]byte, qx, qy, qz *fieldVal) {
+	// Split every scalar with the endomorphism, negating the point when the
+	// half scalar is negative, so the scalars are about 128 bits long.
+	n := 2 * len(xs)
+	points := make([][3]fieldVal, 0, n)
+	ks := make([][]byte, 0, n)
+	maxBits := 0
+	for i := range xs {
+		px, py := curve.bigAffineToField(xs[i], ys[i])
+		k1, k2, signK1, signK2 := curve.splitK(curve.moduloReduce(scalars[i]))
+
+		var p1, p2 [3]fieldVal
+		p1[0].Set(px)
+		p1[1].Set(py)
+		p1[2].SetInt(1)
+		p2[0].Mul2(px, curve.beta).Normalize()
+		p2[1].Set(py)
+		p2[2].SetInt(1)
+		if signK1 < 0 {
+			p1[1].Negate(1).Normalize()
+		}
+		if signK2 < 0 {
+			p2[1].Negate(1).Normalize()
+		}
+		points = append(points, p1, p2)
+		ks = append(ks, k1, k2)
+		for _, k := range [][]byte{k1, k2} {
+			if l := len(k) * 8; l > maxBits {
+				maxBits = l
+			}
+		}
+	}
+
+	// A window of about log2(n) bits balances the additions into the
+	// buckets against the additions of the buckets themselves. The width is
+	// computed signed so that a small n clamps to the minimum instead of
+	// wrapping around.
+	width := bits.Len(uint(n)) - 3
+	if width < 2 {
+		width = 2
+	}
+	if width > 16 {
+		width = 16
+	}
+	c := uint(width)
+
+	qx.Zero()
+	qy.Zero()
+	qz.Zero()
+	buckets := make([][3]fieldVal, 1<<c-1)
+	var px, py, pz fieldVal
+	var rx, ry, rz, ax, ay, az fieldVal
+	windows := (maxBits + int(c) - 1) / int(c)
+	for w := windows - 1; w >= 0; w-- {
+		for i := uint(0); i < c; i++ {
+			curve.doubleJacobian(qx, qy, qz, qx, qy, qz)
+		}
+
+		for i := range buckets {
+			buckets[i][0].Zero()
+			buckets[i][1].Zero()
+			buckets[i][2].Zero()
+		}
+		for i := range points {
+			digit := windowDigit(ks[i], uint(w)*c, c)
+			if digit == 0 {
+				continue
+			}
+			b, p := &buckets[digit-1], &points[i]
+			px.Set(&p[0])
+			py.Set(&p[1])
+			pz.Set(&p[2])
+			curve.addJacobian(&b[0], &b[1], &b[2], &px, &py, &pz,
+				&b[0], &b[1], &b[2])
+		}
+
+		// sum(j * bucket[j-1]) = sum over j of the running sums
+		// bucket[m-1] + ... + bucket[j-1].
+		rx.Zero()
+		ry.Zero()
+		rz.Zero()
+		ax.Zero()
+		ay.Zero()
+		az.Zero()
+		for j := len(buckets) - 1; j >= 0; j-- {
+			b := &buckets[j]
+			curve.addJacobian(&rx, &ry, &rz, &b[0], &b[1], &b[2], &rx, &ry, &rz)
+			curve.addJacobian(&ax, &ay, &az, &rx, &ry, &rz, &ax, &ay, &az)
+		}
+		curve.addJacobian(qx, qy, qz, &ax, &ay, &az, qx, qy, qz)
+	}
+}
+
+// windowDigit returns the c bits of the big endian integer k starting at bit
+// offset off from the least significant bit.
+func windowDigit(k []byte, off, c uint) uint {
+	var digit uint
+	for i := uint(0); i < c; i++ {
+		bit := off + i
+		byteIdx := len(k) - 1 - int(bit/8)
+		if byteIdx < 0 {
+			break
+		}
+		digit |= uint(k[byteIdx]>>(bit%8)&1) << i
+	}
+	return digit
+}
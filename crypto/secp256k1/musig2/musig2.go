@@ -0,0 +1,446 @@
+package musig2
+
+// References:
+//   [BIP-327]: MuSig2 for BIP340-compatible Multi-Signatures
+//   https://github.com/bitcoin/bips/blob/master/bip-0327.mediawiki
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"github.com/w3liu/go-common/crypto/secp256k1"
+	"io"
+	"sort"
+)
+
+// These constants define the sizes of the serialized MuSig2 values.
+const (
+	PubNonceSize   = 66
+	SecNonceSize   = 97
+	PartialSigSize = 32
+)
+
+// Tags of the BIP-327 tagged hashes.
+var (
+	tagKeyAggList   = []byte("KeyAgg list")
+	tagKeyAggCoef   = []byte("KeyAgg coefficient")
+	tagAux          = []byte("MuSig/aux")
+	tagNonce        = []byte("MuSig/nonce")
+	tagNonceCoef    = []byte("MuSig/noncecoef")
+	tagBIP0340Chall = []byte("BIP0340/challenge")
+)
+
+var (
+	// ErrNonceReused is returned when a secret nonce is used to sign a second
+	// time.  Sign clears the secret nonce it is given, since signing two
+	// messages with the same nonce reveals the private key.
+	ErrNonceReused = errors.New("secret nonce has already been used")
+
+	// ErrSignerNotFound is returned when the signer's public key is not one of
+	// the aggregated keys.
+	ErrSignerNotFound = errors.New("signer's public key is not in the list of public keys")
+)
+
+// SortKeys returns the 33-byte compressed public keys sorted
+// lexicographically, which makes the aggregate key independent of the order
+// the signers are listed in.
+func SortKeys(pubKeys [][]byte) [][]byte {
+	sorted := make([][]byte, len(pubKeys))
+	copy(sorted, pubKeys)
+	sort.Slice(sorted, func(i, j int) bool {
+		return bytes.Compare(sorted[i], sorted[j]) < 0
+	})
+	return sorted
+}
+
+// AggregateKey is the public key shared by a group of signers.  Signatures
+// produced by the group verify as BIP-340 signatures for XOnly.
+type AggregateKey struct {
+	q       secp256k1.Point
+	pubKeys [][]byte
+	list    []byte
+	second  []byte
+}
+
+// AggregateKeys combines the 33-byte compressed public keys of the signers
+// into their aggregate key.  The order of the keys matters; use SortKeys
+// first if the signers do not agree on an order some other way.
+func AggregateKeys(pubKeys [][]byte) (*AggregateKey, error) {
+	if len(pubKeys) == 0 {
+		return nil, errors.New("no public keys to aggregate")
+	}
+	points := make([]*secp256k1.Point, len(pubKeys))
+	for i, pk := range pubKeys {
+		p, err := parsePubKey(pk)
+		if err != nil {
+			return nil, fmt.Errorf("public key %d: %v", i, err)
+		}
+		points[i] = p
+	}
+
+	k := &AggregateKey{
+		pubKeys: make([][]byte, len(pubKeys)),
+		list:    secp256k1.TaggedHash(tagKeyAggList, pubKeys...),
+	}
+	copy(k.pubKeys, pubKeys)
+	// The second distinct key gets the coefficient 1, which saves a scalar
+	// multiplication.  If all keys are equal no key matches the zero value.
+	k.second = make([]byte, secp256k1.PubKeyBytesLenCompressed)
+	for _, pk := range pubKeys[1:] {
+		if !bytes.Equal(pk, pubKeys[0]) {
+			k.second = pk
+			break
+		}
+	}
+
+	// Q = a_1*P_1 + ... + a_u*P_u
+	var aP secp256k1.Point
+	for i, p := range points {
+		aP.ScalarMult(k.coefficient(pubKeys[i]), p)
+		k.q.Add(&k.q, &aP)
+	}
+	if k.q.IsInfinity() {
+		return nil, errors.New("aggregate key is the point at infinity")
+	}
+	return k, nil
+}
+
+// coefficient returns the key aggregation coefficient of the public key.
+func (k *AggregateKey) coefficient(pubKey []byte) *secp256k1.Scalar {
+	if bytes.Equal(pubKey, k.second) {
+		return secp256k1.NewScalar(1)
+	}
+	return new(secp256k1.Scalar).SetBytes(secp256k1.TaggedHash(tagKeyAggCoef, k.list, pubKey))
+}
+
+// contains returns whether or not the public key is one of the aggregated
+// keys.
+func (k *AggregateKey) contains(pubKey []byte) bool {
+	for _, pk := range k.pubKeys {
+		if bytes.Equal(pk, pubKey) {
+			return true
+		}
+	}
+	return false
+}
+
+// XOnly returns the 32-byte BIP-340 public key the group signs for.
+func (k *AggregateKey) XOnly() []byte {
+	return k.q.SerializeCompressed()[1:]
+}
+
+// PubKey returns the aggregate key as a public key.
+func (k *AggregateKey) PubKey() *secp256k1.PublicKey {
+	return k.q.PubKey()
+}
+
+// NonceOptions holds the optional inputs of GenerateNonce.  None of them are
+// needed for security, but each one that is known when the nonce is generated
+// gives extra protection should the random number generator fail.
+type NonceOptions struct {
+	// PrivKey is the signer's private key.
+	PrivKey *secp256k1.PrivateKey
+
+	// AggKey is the aggregate key that will be signed for.
+	AggKey *AggregateKey
+
+	// Msg is the message that will be signed.  A nil Msg means no message,
+	// which is different from an empty one.
+	Msg []byte
+
+	// Extra is any other data, such as a session id or counter.
+	Extra []byte
+}
+
+// GenerateNonce returns a fresh secret and public nonce for the signer with
+// the 33-byte compressed public key.  The public nonce is sent to the other
+// signers, and the secret nonce is kept for exactly one call to Sign.  A
+// secret nonce must never be stored or reused.
+func GenerateNonce(pubKey []byte, opts NonceOptions) (secNonce, pubNonce []byte, err error) {
+	if !secp256k1.IsCompressedPubKey(pubKey) {
+		return nil, nil, errors.New("public key must be compressed")
+	}
+	randBytes := make([]byte, 32)
+	if _, err := io.ReadFull(rand.Reader, randBytes); err != nil {
+		return nil, nil, err
+	}
+	if opts.PrivKey != nil {
+		mask := secp256k1.TaggedHash(tagAux, randBytes)
+		sk := opts.PrivKey.Serialize()
+		for i := range randBytes {
+			randBytes[i] = sk[i] ^ mask[i]
+		}
+	}
+	var aggPubKey []byte
+	if opts.AggKey != nil {
+		aggPubKey = opts.AggKey.XOnly()
+	}
+
+	secNonce = make([]byte, 0, SecNonceSize)
+	pubNonce = make([]byte, 0, PubNonceSize)
+	for i := 0; i < 2; i++ {
+		// k_i = int(hash_MuSig/nonce(rand || len(pk) || pk || len(aggpk) ||
+		//     aggpk || m_prefixed || len(extra) || extra || i)) mod n
+		var buf bytes.Buffer
+		buf.Write(randBytes)
+		buf.WriteByte(byte(len(pubKey)))
+		buf.Write(pubKey)
+		buf.WriteByte(byte(len(aggPubKey)))
+		buf.Write(aggPubKey)
+		if opts.Msg == nil {
+			buf.WriteByte(0)
+		} else {
+			buf.WriteByte(1)
+			binary.Write(&buf, binary.BigEndian, uint64(len(opts.Msg)))
+			buf.Write(opts.Msg)
+		}
+		binary.Write(&buf, binary.BigEndian, uint32(len(opts.Extra)))
+		buf.Write(opts.Extra)
+		buf.WriteByte(byte(i))
+
+		k := new(secp256k1.Scalar).SetBytes(secp256k1.TaggedHash(tagNonce, buf.Bytes()))
+		if k.IsZero() {
+			return nil, nil, errors.New("calculated nonce is zero")
+		}
+		var r secp256k1.Point
+		r.ScalarBaseMultConstantTime(k)
+		secNonce = append(secNonce, k.Bytes()...)
+		pubNonce = append(pubNonce, r.SerializeCompressed()...)
+	}
+	secNonce = append(secNonce, pubKey...)
+	return secNonce, pubNonce, nil
+}
+
+// AggregateNonces combines the public nonces of all signers into the
+// aggregate nonce, which any one party can compute and send to the signers.
+func AggregateNonces(pubNonces [][]byte) ([]byte, error) {
+	var r1, r2 secp256k1.Point
+	for i, nonce := range pubNonces {
+		p1, p2, err := parsePubNonce(nonce)
+		if err != nil {
+			return nil, fmt.Errorf("public nonce %d: %v", i, err)
+		}
+		r1.Add(&r1, p1)
+		r2.Add(&r2, p2)
+	}
+	aggNonce := make([]byte, 0, PubNonceSize)
+	aggNonce = append(aggNonce, serializePointExt(&r1)...)
+	return append(aggNonce, serializePointExt(&r2)...), nil
+}
+
+// Session holds the values the signers of one message share once the
+// aggregate nonce is known.
+type Session struct {
+	key *AggregateKey
+	msg []byte
+
+	b, e secp256k1.Scalar
+	r    secp256k1.Point
+}
+
+// NewSession returns the signing session for the message with the aggregate
+// key and aggregate nonce.
+func NewSession(key *AggregateKey, aggNonce, msg []byte) (*Session, error) {
+	if len(aggNonce) != PubNonceSize {
+		return nil, errors.New("invalid aggregate nonce length")
+	}
+	r1, err := parsePointExt(aggNonce[:33])
+	if err != nil {
+		return nil, err
+	}
+	r2, err := parsePointExt(aggNonce[33:])
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Session{key: key, msg: msg}
+	qx := key.XOnly()
+
+	// b = int(hash_MuSig/noncecoef(aggnonce || xbytes(Q) || m)) mod n
+	s.b.SetBytes(secp256k1.TaggedHash(tagNonceCoef, aggNonce, qx, msg))
+
+	// R = R_1 + b*R_2, or G if that is the point at infinity.
+	var bR2 secp256k1.Point
+	bR2.ScalarMult(&s.b, r2)
+	s.r.Add(r1, &bR2)
+	if s.r.IsInfinity() {
+		s.r.Set(secp256k1.NewGenerator())
+	}
+
+	// e = int(hash_BIP0340/challenge(xbytes(R) || xbytes(Q) || m)) mod n
+	s.e.SetBytes(secp256k1.TaggedHash(tagBIP0340Chall, xBytes(&s.r), qx, msg))
+	return s, nil
+}
+
+// Sign returns the signer's 32-byte partial signature.  The secret nonce is
+// cleared so it cannot be used again, and the partial signature is verified
+// before it is returned.
+func (s *Session) Sign(secNonce []byte, privKey *secp256k1.PrivateKey) ([]byte, error) {
+	if len(secNonce) != SecNonceSize {
+		return nil, errors.New("invalid secret nonce length")
+	}
+	if bytes.Equal(secNonce[:64], make([]byte, 64)) {
+		return nil, ErrNonceReused
+	}
+	k1, err1 := secp256k1.ParseScalar(secNonce[:32])
+	k2, err2 := secp256k1.ParseScalar(secNonce[32:64])
+	pubKey := append([]byte(nil), secNonce[64:]...)
+	for i := range secNonce[:64] {
+		secNonce[i] = 0
+	}
+	if err1 != nil || err2 != nil || k1.IsZero() || k2.IsZero() {
+		return nil, errors.New("invalid secret nonce")
+	}
+
+	var pubNonce []byte
+	for _, k := range []*secp256k1.Scalar{k1, k2} {
+		var r secp256k1.Point
+		r.ScalarBaseMultConstantTime(k)
+		pubNonce = append(pubNonce, r.SerializeCompressed()...)
+	}
+	// k_i = k'_i if R has an even y coordinate, otherwise n - k'_i.
+	if hasOddY(&s.r) {
+		k1.Negate(k1)
+		k2.Negate(k2)
+	}
+
+	if !bytes.Equal(privKey.PubKey().SerializeCompressed(), pubKey) {
+		return nil, errors.New("secret nonce was generated for a different public key")
+	}
+	if !s.key.contains(pubKey) {
+		return nil, ErrSignerNotFound
+	}
+
+	// d = d' if Q has an even y coordinate, otherwise n - d'.
+	d, err := secp256k1.ParseScalar(privKey.Serialize())
+	if err != nil {
+		return nil, err
+	}
+	if hasOddY(&s.key.q) {
+		d.Negate(d)
+	}
+
+	// s = (k_1 + b*k_2 + e*a*d) mod n
+	var sig, ead secp256k1.Scalar
+	ead.Mul(&s.e, s.key.coefficient(pubKey)).Mul(&ead, d)
+	sig.Mul(&s.b, k2).Add(&sig, k1).Add(&sig, &ead)
+
+	partialSig := sig.Bytes()
+	if !s.VerifyPartial(partialSig, pubNonce, pubKey) {
+		return nil, errors.New("partial signature does not verify")
+	}
+	return partialSig, nil
+}
+
+// VerifyPartial reports whether partialSig is a valid partial signature by
+// the signer with the public nonce and 33-byte compressed public key.  It
+// identifies the signer at fault when the aggregate signature is invalid.
+func (s *Session) VerifyPartial(partialSig, pubNonce, pubKey []byte) bool {
+	if len(partialSig) != PartialSigSize || !s.key.contains(pubKey) {
+		return false
+	}
+	sig, err := secp256k1.ParseScalar(partialSig)
+	if err != nil {
+		return false
+	}
+	r1, r2, err := parsePubNonce(pubNonce)
+	if err != nil {
+		return false
+	}
+	p, err := parsePubKey(pubKey)
+	if err != nil {
+		return false
+	}
+
+	// Re = R_1 + b*R_2, negated if R has an odd y coordinate.
+	var re, bR2 secp256k1.Point
+	bR2.ScalarMult(&s.b, r2)
+	re.Add(r1, &bR2)
+	if hasOddY(&s.r) {
+		re.Negate(&re)
+	}
+	// P is negated if Q has an odd y coordinate.
+	if hasOddY(&s.key.q) {
+		p.Negate(p)
+	}
+
+	// s*G == Re + e*a*P
+	var ea secp256k1.Scalar
+	ea.Mul(&s.e, s.key.coefficient(pubKey))
+	var sG, eaP, want secp256k1.Point
+	sG.ScalarBaseMult(sig)
+	eaP.ScalarMult(&ea, p)
+	want.Add(&re, &eaP)
+	return sG.Equals(&want)
+}
+
+// AggregatePartials combines the partial signatures of all signers into a
+// 64-byte BIP-340 signature of the message for the aggregate key.
+func (s *Session) AggregatePartials(partialSigs [][]byte) ([]byte, error) {
+	var sum secp256k1.Scalar
+	for i, partialSig := range partialSigs {
+		if len(partialSig) != PartialSigSize {
+			return nil, fmt.Errorf("partial signature %d: invalid length", i)
+		}
+		sig, err := secp256k1.ParseScalar(partialSig)
+		if err != nil {
+			return nil, fmt.Errorf("partial signature %d: %v", i, err)
+		}
+		sum.Add(&sum, sig)
+	}
+	return append(xBytes(&s.r), sum.Bytes()...), nil
+}
+
+// parsePubKey parses a 33-byte compressed public key.
+func parsePubKey(pubKey []byte) (*secp256k1.Point, error) {
+	if !secp256k1.IsCompressedPubKey(pubKey) {
+		return nil, errors.New("public key must be compressed")
+	}
+	return secp256k1.ParsePoint(pubKey)
+}
+
+// parsePubNonce parses the two points of a public nonce.
+func parsePubNonce(pubNonce []byte) (*secp256k1.Point, *secp256k1.Point, error) {
+	if len(pubNonce) != PubNonceSize {
+		return nil, nil, errors.New("invalid public nonce length")
+	}
+	r1, err := parsePubKey(pubNonce[:33])
+	if err != nil {
+		return nil, nil, err
+	}
+	r2, err := parsePubKey(pubNonce[33:])
+	if err != nil {
+		return nil, nil, err
+	}
+	return r1, r2, nil
+}
+
+// parsePointExt parses a 33-byte compressed point, where 33 zero bytes are the
+// point at infinity.
+func parsePointExt(b []byte) (*secp256k1.Point, error) {
+	if bytes.Equal(b, make([]byte, 33)) {
+		return new(secp256k1.Point), nil
+	}
+	return parsePubKey(b)
+}
+
+// serializePointExt serializes a point in the 33-byte compressed format, with
+// the point at infinity as 33 zero bytes.
+func serializePointExt(p *secp256k1.Point) []byte {
+	if p.IsInfinity() {
+		return make([]byte, 33)
+	}
+	return p.SerializeCompressed()
+}
+
+// xBytes returns the 32-byte x coordinate of a point.
+func xBytes(p *secp256k1.Point) []byte {
+	return p.SerializeCompressed()[1:]
+}
+
+// hasOddY returns whether or not the point has an odd y coordinate.
+func hasOddY(p *secp256k1.Point) bool {
+	return p.SerializeCompressed()[0] == 0x03
+}
@@ -0,0 +1,45 @@
+package pedersen
+
+import (
+	"crypto/sha256"
+	"github.com/w3liu/go-common/crypto/secp256k1"
+)
+
+// h is the second generator used for the committed value.  It is derived by
+// hashing the uncompressed encoding of G and taking the first hash, rehashed
+// as needed, that is the x coordinate of a point with an even y coordinate,
+// so nobody knows its discrete logarithm with respect to G.  This gives the
+// same point as the NUMS point of BIP-341.
+var h = func() *secp256k1.Point {
+	g := secp256k1.NewGenerator().PubKey().SerializeUncompressed()
+	x := sha256.Sum256(g)
+	for {
+		if p, err := secp256k1.ParsePoint(append([]byte{0x02}, x[:]...)); err == nil {
+			return p
+		}
+		x = sha256.Sum256(x[:])
+	}
+}()
+
+// H returns the generator used for the committed value.
+func H() *secp256k1.Point {
+	return new(secp256k1.Point).Set(h)
+}
+
+// Commit returns the Pedersen commitment value*H + blind*G.  The commitment
+// hides the value as long as the blinding factor is random and secret, and
+// binds the committer to it.  Commitments are additively homomorphic: the sum
+// of two commitments commits to the sum of the values with the sum of the
+// blinding factors.
+func Commit(value, blind *secp256k1.Scalar) *secp256k1.Point {
+	var vH, bG secp256k1.Point
+	vH.ScalarMultConstantTime(value, h)
+	bG.ScalarBaseMultConstantTime(blind)
+	return new(secp256k1.Point).Add(&vH, &bG)
+}
+
+// Open reports whether the commitment c opens to the value with the blinding
+// factor.
+func Open(c *secp256k1.Point, value, blind *secp256k1.Scalar) bool {
+	return c.Equals(Commit(value, blind))
+}
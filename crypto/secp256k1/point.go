@@ -0,0 +1,326 @@
+package secp256k1
+
+import (
+	"crypto/rand"
+	"errors"
+	"io"
+	"math/big"
+)
+
+// Scalar is an integer modulo the group order N.  The zero value is zero and
+// ready to use.  The methods follow the big.Int convention of storing the
+// result in the receiver and returning it, so calls can be chained.
+type Scalar struct {
+	v big.Int
+}
+
+// NewScalar returns the scalar v mod N.
+func NewScalar(v uint64) *Scalar {
+	s := new(Scalar)
+	s.v.SetUint64(v)
+	s.v.Mod(&s.v, S256().N)
+	return s
+}
+
+// RandomScalar returns a uniformly random scalar in [1, N-1].
+func RandomScalar() (*Scalar, error) {
+	N := S256().N
+	b := make([]byte, 32)
+	s := new(Scalar)
+	for {
+		if _, err := io.ReadFull(rand.Reader, b); err != nil {
+			return nil, err
+		}
+		s.v.SetBytes(b)
+		if s.v.Sign() > 0 && s.v.Cmp(N) < 0 {
+			return s, nil
+		}
+	}
+}
+
+// ParseScalar returns the scalar for the 32-byte big endian integer b, which
+// must be less than N.
+func ParseScalar(b []byte) (*Scalar, error) {
+	if len(b) != 32 {
+		return nil, errors.New("invalid scalar length")
+	}
+	s := new(Scalar)
+	s.v.SetBytes(b)
+	if s.v.Cmp(S256().N) >= 0 {
+		return nil, errors.New("scalar is not less than N")
+	}
+	return s, nil
+}
+
+// SetBytes sets the scalar to the big endian integer b reduced mod N, which
+// is how a hash is turned into a scalar.
+func (s *Scalar) SetBytes(b []byte) *Scalar {
+	s.v.SetBytes(b)
+	s.v.Mod(&s.v, S256().N)
+	return s
+}
+
+// Set sets the scalar to a.
+func (s *Scalar) Set(a *Scalar) *Scalar {
+	s.v.Set(&a.v)
+	return s
+}
+
+// Add sets the scalar to a + b mod N.
+func (s *Scalar) Add(a, b *Scalar) *Scalar {
+	s.v.Add(&a.v, &b.v)
+	s.v.Mod(&s.v, S256().N)
+	return s
+}
+
+// Sub sets the scalar to a - b mod N.
+func (s *Scalar) Sub(a, b *Scalar) *Scalar {
+	s.v.Sub(&a.v, &b.v)
+	s.v.Mod(&s.v, S256().N)
+	return s
+}
+
+// Mul sets the scalar to a * b mod N.
+func (s *Scalar) Mul(a, b *Scalar) *Scalar {
+	s.v.Mul(&a.v, &b.v)
+	s.v.Mod(&s.v, S256().N)
+	return s
+}
+
+// Negate sets the scalar to -a mod N.
+func (s *Scalar) Negate(a *Scalar) *Scalar {
+	s.v.Neg(&a.v)
+	s.v.Mod(&s.v, S256().N)
+	return s
+}
+
+// Inverse sets the scalar to a^-1 mod N.  The inverse of zero is zero.
+func (s *Scalar) Inverse(a *Scalar) *Scalar {
+	if a.v.Sign() == 0 {
+		s.v.SetInt64(0)
+		return s
+	}
+	s.v.ModInverse(&a.v, S256().N)
+	return s
+}
+
+// IsZero returns whether or not the scalar is zero.
+func (s *Scalar) IsZero() bool {
+	return s.v.Sign() == 0
+}
+
+// Equals returns whether or not the two scalars are the same.
+func (s *Scalar) Equals(a *Scalar) bool {
+	return s.v.Cmp(&a.v) == 0
+}
+
+// Bytes returns the scalar as a 32-byte big endian integer.
+func (s *Scalar) Bytes() []byte {
+	return paddedAppend(32, nil, s.v.Bytes())
+}
+
+// BigInt returns the scalar as a new big.Int.
+func (s *Scalar) BigInt() *big.Int {
+	return new(big.Int).Set(&s.v)
+}
+
+// Point is a point on the secp256k1 curve kept in Jacobian coordinates, so
+// chains of additions and multiplications need no field inversions and no
+// big.Int conversions.  The zero value is the point at infinity.  Like
+// Scalar, the methods store the result in the receiver and return it.
+type Point struct {
+	x, y, z fieldVal
+}
+
+// NewGenerator returns the base point G.
+func NewGenerator() *Point {
+	curve := S256()
+	gx, gy := curve.bigAffineToField(curve.Gx, curve.Gy)
+	p := new(Point)
+	p.x.Set(gx)
+	p.y.Set(gy)
+	p.z.SetInt(1)
+	return p
+}
+
+// NewPointFromAffine returns the point with the affine coordinates (x, y),
+// which must be on the curve.
+func NewPointFromAffine(x, y *big.Int) (*Point, error) {
+	curve := S256()
+	if x.Sign() == 0 && y.Sign() == 0 {
+		return new(Point), nil
+	}
+	if !curve.IsOnCurve(x, y) {
+		return nil, errors.New("point is not on the curve")
+	}
+	fx, fy := curve.bigAffineToField(x, y)
+	p := new(Point)
+	p.x.Set(fx)
+	p.y.Set(fy)
+	p.z.SetInt(1)
+	return p, nil
+}
+
+// ParsePoint parses a point serialized as a SEC 1 compressed or uncompressed
+// public key, or as the single byte 0x00 for the point at infinity.
+func ParsePoint(b []byte) (*Point, error) {
+	if len(b) == 1 && b[0] == 0 {
+		return new(Point), nil
+	}
+	pubKey, err := ParsePubKey(b)
+	if err != nil {
+		return nil, err
+	}
+	return NewPointFromAffine(pubKey.X, pubKey.Y)
+}
+
+// Set sets the point to a.
+func (p *Point) Set(a *Point) *Point {
+	p.x.Set(&a.x)
+	p.y.Set(&a.y)
+	p.z.Set(&a.z)
+	return p
+}
+
+// IsInfinity returns whether or not the point is the point at infinity.
+func (p *Point) IsInfinity() bool {
+	return p.z.Normalize().IsZero()
+}
+
+// Add sets the point to a + b.
+func (p *Point) Add(a, b *Point) *Point {
+	// Copy the inputs since addJacobian normalizes them in place and the
+	// receiver may alias either of them.
+	var a2, b2 Point
+	a2.Set(a)
+	b2.Set(b)
+	S256().addJacobian(&a2.x, &a2.y, &a2.z, &b2.x, &b2.y, &b2.z, &p.x, &p.y, &p.z)
+	return p
+}
+
+// Sub sets the point to a - b.
+func (p *Point) Sub(a, b *Point) *Point {
+	var negB Point
+	negB.Negate(b)
+	return p.Add(a, &negB)
+}
+
+// Double sets the point to 2*a.
+func (p *Point) Double(a *Point) *Point {
+	var a2 Point
+	a2.Set(a)
+	S256().doubleJacobian(&a2.x, &a2.y, &a2.z, &p.x, &p.y, &p.z)
+	return p
+}
+
+// Negate sets the point to -a.
+func (p *Point) Negate(a *Point) *Point {
+	p.x.Set(&a.x)
+	p.y.Set(&a.y).Normalize().Negate(1).Normalize()
+	p.z.Set(&a.z)
+	return p
+}
+
+// ScalarMult sets the point to k*a.  It is variable time and must only be
+// used when k is public, see ScalarMultConstantTime otherwise.
+func (p *Point) ScalarMult(k *Scalar, a *Point) *Point {
+	if a.IsInfinity() {
+		return p.Set(a)
+	}
+	var aff Point
+	aff.Set(a).toAffine()
+	S256().scalarMultJacobian(&aff.x, &aff.y, k.v.Bytes(), &p.x, &p.y, &p.z)
+	return p
+}
+
+// ScalarMultConstantTime sets the point to k*a without leaking k through
+// timing.  It should be used when k is secret, such as a blinding factor.
+func (p *Point) ScalarMultConstantTime(k *Scalar, a *Point) *Point {
+	if a.IsInfinity() {
+		return p.Set(a)
+	}
+	var aff Point
+	aff.Set(a).toAffine()
+	S256().scalarMultConstantTimeJacobian(&aff.x, &aff.y, k.Bytes(), &p.x, &p.y, &p.z)
+	return p
+}
+
+// ScalarBaseMult sets the point to k*G.  It is variable time and must only be
+// used when k is public, see ScalarBaseMultConstantTime otherwise.
+func (p *Point) ScalarBaseMult(k *Scalar) *Point {
+	S256().scalarBaseMultJacobian(k.v.Bytes(), &p.x, &p.y, &p.z)
+	return p
+}
+
+// ScalarBaseMultConstantTime sets the point to k*G without leaking k through
+// timing.
+func (p *Point) ScalarBaseMultConstantTime(k *Scalar) *Point {
+	S256().scalarBaseMultConstantTimeJacobian(k.Bytes(), &p.x, &p.y, &p.z)
+	return p
+}
+
+// Equals returns whether or not the two points are the same.  Jacobian points
+// are compared without inversions by checking X1*Z2^2 == X2*Z1^2 and
+// Y1*Z2^3 == Y2*Z1^3.
+func (p *Point) Equals(a *Point) bool {
+	pInf, aInf := p.IsInfinity(), a.IsInfinity()
+	if pInf || aInf {
+		return pInf == aInf
+	}
+
+	var z1z1, z2z2, u1, u2, s1, s2 fieldVal
+	z1z1.SquareVal(&p.z)
+	z2z2.SquareVal(&a.z)
+	u1.Set(&p.x).Mul(&z2z2).Normalize()
+	u2.Set(&a.x).Mul(&z1z1).Normalize()
+	s1.Set(&p.y).Mul(&z2z2).Mul(&a.z).Normalize()
+	s2.Set(&a.y).Mul(&z1z1).Mul(&p.z).Normalize()
+	return u1.Equals(&u2) && s1.Equals(&s2)
+}
+
+// toAffine converts the point to affine coordinates in place, so z is 1.  The
+// point must not be the point at infinity.
+func (p *Point) toAffine() *Point {
+	var zInv, zInv2 fieldVal
+	zInv.Set(&p.z).Inverse()
+	zInv2.SquareVal(&zInv)
+	p.x.Mul(&zInv2).Normalize()
+	p.y.Mul(zInv2.Mul(&zInv)).Normalize()
+	p.z.SetInt(1)
+	return p
+}
+
+// AffineCoords returns the affine coordinates of the point.  The point at
+// infinity is returned as (0, 0).
+func (p *Point) AffineCoords() (*big.Int, *big.Int) {
+	if p.IsInfinity() {
+		return new(big.Int), new(big.Int)
+	}
+	var aff Point
+	aff.Set(p).toAffine()
+	return new(big.Int).SetBytes(aff.x.Bytes()[:]), new(big.Int).SetBytes(aff.y.Bytes()[:])
+}
+
+// SerializeCompressed serializes the point in the 33-byte SEC 1 compressed
+// format, or as the single byte 0x00 for the point at infinity.
+func (p *Point) SerializeCompressed() []byte {
+	if p.IsInfinity() {
+		return []byte{0x00}
+	}
+	var aff Point
+	aff.Set(p).toAffine()
+	b := make([]byte, 0, PubKeyBytesLenCompressed)
+	format := pubkeyCompressed
+	if aff.y.IsOdd() {
+		format |= 0x1
+	}
+	b = append(b, format)
+	return append(b, aff.x.Bytes()[:]...)
+}
+
+// PubKey returns the point as a public key.  It must not be the point at
+// infinity.
+func (p *Point) PubKey() *PublicKey {
+	x, y := p.AffineCoords()
+	return &PublicKey{Curve: S256(), X: x, Y: y}
+}
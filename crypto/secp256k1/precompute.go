@@ -6,8 +6,20 @@ import (
 	"encoding/binary"
 	"io/ioutil"
 	"strings"
+	"sync"
 )
 
+//go:generate go run -tags gensecp256k1 genprecomps.go
+
+// lazyBytePointsOnce guards computing the byte points on first use when no
+// pre-computed table is compiled in.
+var lazyBytePointsOnce sync.Once
+
+// loadS256BytePoints decodes the pre-computed table compiled into
+// secp256k1.go.  There is no table when generating it or when building with
+// the lazysecp256k1 tag, in which case getBytePoints computes it from the
+// curve on first use.  That trades about 1MB of binary size and the decoding
+// at initialization for a slower first ScalarBaseMult.
 func loadS256BytePoints() error {
 	// There will be no byte points to load when generating them.
 	bp := secp256k1BytePoints
@@ -15,14 +27,7 @@ func loadS256BytePoints() error {
 		return nil
 	}
 
-	// Decompress the pre-computed table used to accelerate scalar base
-	// multiplication.
-	decoder := base64.NewDecoder(base64.StdEncoding, strings.NewReader(bp))
-	r, err := zlib.NewReader(decoder)
-	if err != nil {
-		return err
-	}
-	serialized, err := ioutil.ReadAll(r)
+	serialized, err := decodeBytePoints(bp)
 	if err != nil {
 		return err
 	}
@@ -53,3 +58,27 @@ func loadS256BytePoints() error {
 	secp256k1.bytePoints = &bytePoints
 	return nil
 }
+
+// decodeBytePoints decompresses the base64 and zlib encoded serialized byte
+// points written by genprecomps.go.
+func decodeBytePoints(bp string) ([]byte, error) {
+	// Decompress the pre-computed table used to accelerate scalar base
+	// multiplication.
+	decoder := base64.NewDecoder(base64.StdEncoding, strings.NewReader(bp))
+	r, err := zlib.NewReader(decoder)
+	if err != nil {
+		return nil, err
+	}
+	return ioutil.ReadAll(r)
+}
+
+// getBytePoints returns the byte points used to accelerate ScalarBaseMult,
+// computing them on first use when no pre-computed table was loaded.
+func (curve *KoblitzCurve) getBytePoints() *[32][256][3]fieldVal {
+	lazyBytePointsOnce.Do(func() {
+		if curve.bytePoints == nil {
+			curve.bytePoints = curve.computeBytePoints()
+		}
+	})
+	return curve.bytePoints
+}
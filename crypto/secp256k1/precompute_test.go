@@ -0,0 +1,64 @@
+package secp256k1
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+)
+
+// TestBytePointsMatchCurve ensures the pre-computed table compiled into
+// secp256k1.go matches the table generated from the curve, so it can be
+// regenerated with go generate.
+func TestBytePointsMatchCurve(t *testing.T) {
+	if secp256k1BytePoints == "" {
+		t.Skip("built without a pre-computed table")
+	}
+	embedded, err := decodeBytePoints(secp256k1BytePoints)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(embedded, S256().SerializedBytePoints()) {
+		t.Fatal("embedded byte points do not match the curve, run go generate")
+	}
+}
+
+// TestEndomorphismVectors ensures the hard-coded endomorphism constants match
+// the ones derived from the curve.
+func TestEndomorphismVectors(t *testing.T) {
+	curve := S256()
+	a1, b1, a2, b2 := curve.EndomorphismVectors()
+	if a1.Cmp(curve.a1) != 0 || b1.Cmp(curve.b1) != 0 ||
+		a2.Cmp(curve.a2) != 0 || b2.Cmp(curve.b2) != 0 {
+		t.Fatalf("got (%x, %x, %x, %x), want (%x, %x, %x, %x)", a1, b1, a2, b2,
+			curve.a1, curve.b1, curve.a2, curve.b2)
+	}
+}
+
+// TestPippengerFewPoints ensures the bucket method picks a valid window for
+// fewer points than the multi-scalar threshold ever passes to it.
+func TestPippengerFewPoints(t *testing.T) {
+	curve := S256()
+	for n := 1; n <= 3; n++ {
+		xs := make([]*big.Int, n)
+		ys := make([]*big.Int, n)
+		scalars := make([][]byte, n)
+		wantX, wantY := new(big.Int), new(big.Int)
+		for i := range xs {
+			k := []byte{byte(i + 2), 0x55, 0xaa}
+			xs[i], ys[i] = curve.ScalarBaseMult([]byte{byte(i + 7)})
+			scalars[i] = k
+			x, y := curve.ScalarMult(xs[i], ys[i], k)
+			if i == 0 {
+				wantX, wantY = x, y
+			} else {
+				wantX, wantY = curve.Add(wantX, wantY, x, y)
+			}
+		}
+		var qx, qy, qz fieldVal
+		curve.pippenger(xs, ys, scalars, &qx, &qy, &qz)
+		x, y := curve.fieldJacobianToBigAffine(&qx, &qy, &qz)
+		if x.Cmp(wantX) != 0 || y.Cmp(wantY) != 0 {
+			t.Fatalf("n = %d: got (%x, %x), want (%x, %x)", n, x, y, wantX, wantY)
+		}
+	}
+}
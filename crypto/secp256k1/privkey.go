@@ -0,0 +1,71 @@
+package secp256k1
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+)
+
+// PrivKeyBytesLen defines the length in bytes of a serialized private key.
+const PrivKeyBytesLen = 32
+
+// PrivateKey wraps an ecdsa.PrivateKey as a convenience mainly for signing
+// things with the private key without having to directly import the ecdsa
+// package.
+type PrivateKey ecdsa.PrivateKey
+
+// PrivKeyFromBytes returns the secp256k1 private key for the 32-byte big endian
+// scalar pk.  The scalar must be in the range [1, N-1].
+func PrivKeyFromBytes(pk []byte) (*PrivateKey, error) {
+	curve := S256()
+	if len(pk) != PrivKeyBytesLen {
+		return nil, errors.New("invalid private key length")
+	}
+	d := new(big.Int).SetBytes(pk)
+	if d.Sign() == 0 || d.Cmp(curve.N) >= 0 {
+		return nil, errors.New("private key is not in [1, N-1]")
+	}
+
+	x, y := curve.ScalarBaseMultConstantTime(pk)
+	priv := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: curve,
+			X:     x,
+			Y:     y,
+		},
+		D: d,
+	}
+	return (*PrivateKey)(priv), nil
+}
+
+// GeneratePrivateKey returns a new random secp256k1 private key.
+func GeneratePrivateKey() (*PrivateKey, error) {
+	key, err := NewPrivateKey(S256())
+	if err != nil {
+		return nil, err
+	}
+	return (*PrivateKey)(key), nil
+}
+
+// PubKey returns the PublicKey corresponding to this private key.
+func (p *PrivateKey) PubKey() *PublicKey {
+	return (*PublicKey)(&p.PublicKey)
+}
+
+// ToECDSA returns the private key as a *ecdsa.PrivateKey.
+func (p *PrivateKey) ToECDSA() *ecdsa.PrivateKey {
+	return (*ecdsa.PrivateKey)(p)
+}
+
+// Sign generates a deterministic, low-S ECDSA signature for the provided hash
+// using the private key.  See Sign for details.
+func (p *PrivateKey) Sign(hash []byte) (*Signature, error) {
+	return signRFC6979(p.ToECDSA(), hash)
+}
+
+// Serialize returns the private key number d as a big-endian binary-encoded
+// number, padded to a length of 32 bytes.
+func (p *PrivateKey) Serialize() []byte {
+	b := make([]byte, 0, PrivKeyBytesLen)
+	return paddedAppend(PrivKeyBytesLen, b, p.D.Bytes())
+}
@@ -0,0 +1,281 @@
+package secp256k1
+
+import (
+	"crypto/ecdsa"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// These constants define the sizes of BIP-340 public keys and signatures.
+const (
+	SchnorrPubKeySize    = 32
+	SchnorrSignatureSize = 64
+)
+
+// Tags of the BIP-340 tagged hashes.
+var (
+	tagBIP0340Aux       = []byte("BIP0340/aux")
+	tagBIP0340Nonce     = []byte("BIP0340/nonce")
+	tagBIP0340Challenge = []byte("BIP0340/challenge")
+)
+
+// TaggedHash implements the tagged hash scheme of BIP-340:
+// SHA256(SHA256(tag) || SHA256(tag) || msgs...).
+func TaggedHash(tag []byte, msgs ...[]byte) []byte {
+	tagHash := sha256.Sum256(tag)
+	h := sha256.New()
+	h.Write(tagHash[:])
+	h.Write(tagHash[:])
+	for _, msg := range msgs {
+		h.Write(msg)
+	}
+	return h.Sum(nil)
+}
+
+// SerializeXOnly serializes a public key as the 32-byte x coordinate used by
+// BIP-340.  The y coordinate is implied to be even.
+func (p *PublicKey) SerializeXOnly() []byte {
+	return paddedAppend(32, make([]byte, 0, SchnorrPubKeySize), p.X.Bytes())
+}
+
+// ParseXOnlyPubKey parses a 32-byte BIP-340 public key.  The returned key is
+// the point with the given x coordinate and an even y coordinate.
+func ParseXOnlyPubKey(pubKey []byte) (*PublicKey, error) {
+	if len(pubKey) != SchnorrPubKeySize {
+		return nil, errors.New("invalid x-only pubkey length")
+	}
+	x, y, err := liftX(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return &PublicKey{Curve: S256(), X: x, Y: y}, nil
+}
+
+// liftX returns the point with the 32-byte x coordinate b and an even y
+// coordinate.
+func liftX(b []byte) (*big.Int, *big.Int, error) {
+	curve := S256()
+	x := new(big.Int).SetBytes(b)
+	if x.Cmp(curve.P) >= 0 {
+		return nil, nil, errors.New("x coordinate is >= to P")
+	}
+	y, err := decompressPoint(curve, x, false)
+	if err != nil {
+		return nil, nil, errors.New("x coordinate is not on the curve")
+	}
+	return x, y, nil
+}
+
+// SchnorrSign produces a BIP-340 signature of msg with the private key.  The
+// 32-byte auxRand is mixed into the nonce derivation to protect against side
+// channel attacks; it should be fresh randomness, and a nil auxRand is
+// replaced with random bytes.  The signature is verified before it is
+// returned.
+func SchnorrSign(privKey *ecdsa.PrivateKey, msg, auxRand []byte) ([]byte, error) {
+	curve := S256()
+	N := curve.N
+
+	if privKey == nil || privKey.D == nil || privKey.D.Sign() <= 0 ||
+		privKey.D.Cmp(N) >= 0 {
+		return nil, errors.New("invalid private key")
+	}
+	if auxRand == nil {
+		auxRand = make([]byte, 32)
+		if _, err := rand.Read(auxRand); err != nil {
+			return nil, err
+		}
+	}
+	if len(auxRand) != 32 {
+		return nil, errors.New("auxiliary randomness must be 32 bytes")
+	}
+
+	// d = d' if P has an even y coordinate, otherwise n - d'.
+	px, py := curve.ScalarBaseMultConstantTime(privKey.D.Bytes())
+	d := new(big.Int).Set(privKey.D)
+	if isOdd(py) {
+		d.Sub(N, d)
+	}
+	dBytes := paddedAppend(32, nil, d.Bytes())
+	pBytes := paddedAppend(32, nil, px.Bytes())
+
+	// t = bytes(d) xor hash_BIP0340/aux(a)
+	t := TaggedHash(tagBIP0340Aux, auxRand)
+	for i := range t {
+		t[i] ^= dBytes[i]
+	}
+
+	// k' = int(hash_BIP0340/nonce(t || bytes(P) || m)) mod n
+	k := new(big.Int).SetBytes(TaggedHash(tagBIP0340Nonce, t, pBytes, msg))
+	k.Mod(k, N)
+	if k.Sign() == 0 {
+		return nil, errors.New("calculated nonce is zero")
+	}
+
+	// R = k'*G, k = k' if R has an even y coordinate, otherwise n - k'.
+	rx, ry := curve.ScalarBaseMultConstantTime(k.Bytes())
+	if isOdd(ry) {
+		k.Sub(N, k)
+	}
+	rBytes := paddedAppend(32, nil, rx.Bytes())
+
+	// e = int(hash_BIP0340/challenge(bytes(R) || bytes(P) || m)) mod n
+	e := new(big.Int).SetBytes(TaggedHash(tagBIP0340Challenge, rBytes, pBytes, msg))
+	e.Mod(e, N)
+
+	// sig = bytes(R) || bytes((k + e*d) mod n)
+	s := e.Mul(e, d)
+	s.Add(s, k)
+	s.Mod(s, N)
+	sig := paddedAppend(32, rBytes, s.Bytes())
+
+	if err := schnorrVerify(pBytes, msg, sig); err != nil {
+		return nil, err
+	}
+	return sig, nil
+}
+
+// SchnorrVerify reports whether sig is a valid BIP-340 signature of msg for
+// the 32-byte x-only public key.
+func SchnorrVerify(pubKey, msg, sig []byte) bool {
+	return schnorrVerify(pubKey, msg, sig) == nil
+}
+
+// schnorrVerify verifies a BIP-340 signature and returns the reason it is
+// invalid, if any.
+func schnorrVerify(pubKey, msg, sig []byte) error {
+	curve := S256()
+
+	if len(pubKey) != SchnorrPubKeySize {
+		return errors.New("invalid x-only pubkey length")
+	}
+	if len(sig) != SchnorrSignatureSize {
+		return errors.New("invalid schnorr signature length")
+	}
+	px, py, err := liftX(pubKey)
+	if err != nil {
+		return err
+	}
+	r := new(big.Int).SetBytes(sig[:32])
+	if r.Cmp(curve.P) >= 0 {
+		return errors.New("signature r is >= to P")
+	}
+	s := new(big.Int).SetBytes(sig[32:])
+	if s.Cmp(curve.N) >= 0 {
+		return errors.New("signature s is >= to N")
+	}
+
+	// e = int(hash_BIP0340/challenge(bytes(r) || bytes(P) || m)) mod n
+	e := new(big.Int).SetBytes(TaggedHash(tagBIP0340Challenge, sig[:32], pubKey, msg))
+	e.Mod(e, curve.N)
+
+	// R = s*G - e*P, computed in Jacobian coordinates.
+	e.Sub(curve.N, e)
+	var rx, ry, rz fieldVal
+	curve.combinedMultJacobian(px, py, s.Bytes(), e.Bytes(), &rx, &ry, &rz)
+
+	// Fail if R is infinity, has an odd y coordinate or x(R) != r.
+	if rz.Normalize().IsZero() {
+		return errors.New("calculated R is the point at infinity")
+	}
+	curve.fieldJacobianToBigAffine(&rx, &ry, &rz)
+	if ry.IsOdd() {
+		return errors.New("calculated R y coordinate is odd")
+	}
+	var sigR fieldVal
+	sigR.SetByteSlice(sig[:32])
+	if !rx.Equals(&sigR) {
+		return errors.New("calculated R x coordinate does not match r")
+	}
+	return nil
+}
+
+// SchnorrBatchVerify reports whether all the BIP-340 signatures are valid for
+// their respective public keys and messages.  It is faster than verifying the
+// signatures one by one, but does not tell which one is invalid.
+func SchnorrBatchVerify(pubKeys, msgs, sigs [][]byte) bool {
+	curve := S256()
+	N := curve.N
+
+	n := len(pubKeys)
+	if len(msgs) != n || len(sigs) != n {
+		return false
+	}
+	if n == 0 {
+		return true
+	}
+
+	// The batch equation is
+	//   (s1 + a2*s2 + ... + au*su)*G = R1 + a2*R2 + ... + au*Ru +
+	//                                  e1*P1 + a2*e2*P2 + ... + au*eu*Pu
+	// where a1 = 1 and the others are random coefficients in [1, n-1].  It
+	// is checked by computing the left side minus the right side as a
+	// single multi-scalar multiplication, which must be the point at
+	// infinity.
+	sum := new(big.Int)
+	xs := make([]*big.Int, 0, 2*n+1)
+	ys := make([]*big.Int, 0, 2*n+1)
+	scalars := make([][]byte, 0, 2*n+1)
+	for i := 0; i < n; i++ {
+		pubKey, msg, sig := pubKeys[i], msgs[i], sigs[i]
+		if len(pubKey) != SchnorrPubKeySize || len(sig) != SchnorrSignatureSize {
+			return false
+		}
+		px, py, err := liftX(pubKey)
+		if err != nil {
+			return false
+		}
+		rx, ry, err := liftX(sig[:32])
+		if err != nil {
+			return false
+		}
+		s := new(big.Int).SetBytes(sig[32:])
+		if s.Cmp(N) >= 0 {
+			return false
+		}
+		e := new(big.Int).SetBytes(TaggedHash(tagBIP0340Challenge, sig[:32], pubKey, msg))
+		e.Mod(e, N)
+
+		a := one
+		if i > 0 {
+			if a, err = randScalar(); err != nil {
+				return false
+			}
+		}
+
+		// sum += a*s
+		sum.Add(sum, s.Mul(s, a))
+		sum.Mod(sum, N)
+
+		// -a*R and -a*e*P
+		e.Mul(e, a)
+		e.Mod(e, N)
+		xs = append(xs, rx, px)
+		ys = append(ys, ry, py)
+		scalars = append(scalars, new(big.Int).Sub(N, a).Bytes(),
+			e.Sub(N, e).Bytes())
+	}
+	xs = append(xs, curve.Gx)
+	ys = append(ys, curve.Gy)
+	scalars = append(scalars, sum.Bytes())
+
+	var qx, qy, qz fieldVal
+	curve.multiScalarMultJacobian(xs, ys, scalars, &qx, &qy, &qz)
+	return qz.Normalize().IsZero()
+}
+
+// randScalar returns a uniformly random integer in [1, N-1].
+func randScalar() (*big.Int, error) {
+	N := S256().N
+	b := make([]byte, 32)
+	for {
+		if _, err := rand.Read(b); err != nil {
+			return nil, err
+		}
+		k := new(big.Int).SetBytes(b)
+		if k.Sign() > 0 && k.Cmp(N) < 0 {
+			return k, nil
+		}
+	}
+}
@@ -2,6 +2,9 @@
 // Use of this source code is governed by an ISC
 // license that can be found in the LICENSE file.
 
+//go:build !gensecp256k1 && !lazysecp256k1
+// +build !gensecp256k1,!lazysecp256k1
+
 package secp256k1
 
 // Auto-generated file (see genprecomps.go)
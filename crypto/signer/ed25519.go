@@ -0,0 +1,60 @@
+package signer
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+)
+
+type ed25519Signer struct {
+	key ed25519.PrivateKey
+}
+
+type ed25519Verifier struct {
+	key ed25519.PublicKey
+}
+
+func generateEd25519() (Signer, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &ed25519Signer{key: key}, nil
+}
+
+func newEd25519Signer(privKey []byte) (Signer, error) {
+	if len(privKey) != ed25519.SeedSize {
+		return nil, errors.New("invalid private key length")
+	}
+	return &ed25519Signer{key: ed25519.NewKeyFromSeed(privKey)}, nil
+}
+
+func newEd25519Verifier(pubKey []byte) (Verifier, error) {
+	if len(pubKey) != ed25519.PublicKeySize {
+		return nil, errors.New("invalid public key length")
+	}
+	return &ed25519Verifier{key: append(ed25519.PublicKey(nil), pubKey...)}, nil
+}
+
+func (s *ed25519Signer) Type() KeyType { return Ed25519 }
+
+func (s *ed25519Signer) Bytes() []byte { return s.key.Seed() }
+
+func (s *ed25519Signer) Public() Verifier {
+	return &ed25519Verifier{key: s.key.Public().(ed25519.PublicKey)}
+}
+
+func (s *ed25519Signer) Sign(msg []byte) ([]byte, error) {
+	return ed25519.Sign(s.key, msg), nil
+}
+
+func (v *ed25519Verifier) Type() KeyType { return Ed25519 }
+
+func (v *ed25519Verifier) Bytes() []byte { return append([]byte(nil), v.key...) }
+
+func (v *ed25519Verifier) Verify(msg, sig []byte) bool {
+	if len(sig) != SignatureSize {
+		return false
+	}
+	return ed25519.Verify(v.key, msg, sig)
+}
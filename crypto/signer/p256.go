@@ -0,0 +1,141 @@
+package signer
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+)
+
+// p256HalfOrder is N/2, the largest s of a low-S signature.
+var p256HalfOrder = new(big.Int).Rsh(elliptic.P256().Params().N, 1)
+
+type p256Signer struct {
+	key *ecdsa.PrivateKey
+}
+
+type p256Verifier struct {
+	key *ecdsa.PublicKey
+}
+
+func generateP256() (Signer, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &p256Signer{key: key}, nil
+}
+
+func newP256Signer(privKey []byte) (Signer, error) {
+	curve := elliptic.P256()
+	if len(privKey) != 32 {
+		return nil, errors.New("invalid private key length")
+	}
+	d := new(big.Int).SetBytes(privKey)
+	if d.Sign() == 0 || d.Cmp(curve.Params().N) >= 0 {
+		return nil, errors.New("private key is not in [1, N-1]")
+	}
+	x, y := curve.ScalarBaseMult(privKey)
+	return &p256Signer{key: &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{Curve: curve, X: x, Y: y},
+		D:         d,
+	}}, nil
+}
+
+func newP256Verifier(pubKey []byte) (Verifier, error) {
+	x, y, err := decompressP256(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return &p256Verifier{key: &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}}, nil
+}
+
+func (s *p256Signer) Type() KeyType { return P256 }
+
+func (s *p256Signer) Bytes() []byte { return paddedBytes(s.key.D) }
+
+func (s *p256Signer) Public() Verifier {
+	return &p256Verifier{key: &s.key.PublicKey}
+}
+
+// Sign returns the signature of the SHA-256 hash of msg with s normalized
+// to the lower half of the curve order, like secp256k1 signatures.  The
+// nonce is random, so signing the same message twice gives different
+// signatures.
+func (s *p256Signer) Sign(msg []byte) ([]byte, error) {
+	hash := sha256.Sum256(msg)
+	r, sigS, err := ecdsa.Sign(rand.Reader, s.key, hash[:])
+	if err != nil {
+		return nil, err
+	}
+	if sigS.Cmp(p256HalfOrder) > 0 {
+		sigS.Sub(elliptic.P256().Params().N, sigS)
+	}
+	return append(paddedBytes(r), paddedBytes(sigS)...), nil
+}
+
+func (v *p256Verifier) Type() KeyType { return P256 }
+
+func (v *p256Verifier) Bytes() []byte {
+	b := make([]byte, 0, 33)
+	b = append(b, 0x02|byte(v.key.Y.Bit(0)))
+	return append(b, paddedBytes(v.key.X)...)
+}
+
+// Verify rejects high-S signatures so that every message has a single valid
+// encoding per nonce.
+func (v *p256Verifier) Verify(msg, sig []byte) bool {
+	if len(sig) != SignatureSize {
+		return false
+	}
+	hash := sha256.Sum256(msg)
+	r := new(big.Int).SetBytes(sig[:32])
+	s := new(big.Int).SetBytes(sig[32:])
+	if s.Cmp(p256HalfOrder) > 0 {
+		return false
+	}
+	return ecdsa.Verify(v.key, hash[:], r, s)
+}
+
+// decompressP256 returns the point of a 33-byte SEC 1 compressed P-256 public
+// key.  y is the square root of x^3 - 3x + b, which is (x^3 - 3x + b)^((p+1)/4)
+// since p = 3 mod 4.
+func decompressP256(pubKey []byte) (*big.Int, *big.Int, error) {
+	params := elliptic.P256().Params()
+	if len(pubKey) != 33 || pubKey[0]&^1 != 0x02 {
+		return nil, nil, errors.New("public key must be compressed")
+	}
+	x := new(big.Int).SetBytes(pubKey[1:])
+	if x.Cmp(params.P) >= 0 {
+		return nil, nil, errors.New("x coordinate is >= to P")
+	}
+
+	x3 := new(big.Int).Mul(x, x)
+	x3.Mul(x3, x)
+	threeX := new(big.Int).Lsh(x, 1)
+	threeX.Add(threeX, x)
+	x3.Sub(x3, threeX)
+	x3.Add(x3, params.B)
+	x3.Mod(x3, params.P)
+
+	exp := new(big.Int).Add(params.P, big.NewInt(1))
+	exp.Rsh(exp, 2)
+	y := new(big.Int).Exp(x3, exp, params.P)
+	if new(big.Int).Exp(y, big.NewInt(2), params.P).Cmp(x3) != 0 {
+		return nil, nil, errors.New("x coordinate is not on the curve")
+	}
+	if y.Bit(0) != uint(pubKey[0]&1) {
+		y.Sub(params.P, y)
+	}
+	return x, y, nil
+}
+
+// paddedBytes returns the integer as a 32-byte big endian value.
+func paddedBytes(v *big.Int) []byte {
+	b := make([]byte, 32)
+	vb := v.Bytes()
+	copy(b[32-len(vb):], vb)
+	return b
+}
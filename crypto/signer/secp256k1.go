@@ -0,0 +1,74 @@
+package signer
+
+import (
+	"crypto/sha256"
+	"errors"
+	"github.com/w3liu/go-common/crypto/secp256k1"
+	"math/big"
+)
+
+// secp256k1HalfOrder is N/2, the largest s of a low-S signature.
+var secp256k1HalfOrder = new(big.Int).Rsh(secp256k1.S256().N, 1)
+
+type secp256k1Signer struct {
+	key *secp256k1.PrivateKey
+}
+
+type secp256k1Verifier struct {
+	key *secp256k1.PublicKey
+}
+
+func generateSecp256k1() (Signer, error) {
+	key, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		return nil, err
+	}
+	return &secp256k1Signer{key: key}, nil
+}
+
+func newSecp256k1Signer(privKey []byte) (Signer, error) {
+	key, err := secp256k1.PrivKeyFromBytes(privKey)
+	if err != nil {
+		return nil, err
+	}
+	return &secp256k1Signer{key: key}, nil
+}
+
+func newSecp256k1Verifier(pubKey []byte) (Verifier, error) {
+	if !secp256k1.IsCompressedPubKey(pubKey) {
+		return nil, errors.New("public key must be compressed")
+	}
+	key, err := secp256k1.ParsePubKey(pubKey)
+	if err != nil {
+		return nil, err
+	}
+	return &secp256k1Verifier{key: key}, nil
+}
+
+func (s *secp256k1Signer) Type() KeyType { return Secp256k1 }
+
+func (s *secp256k1Signer) Bytes() []byte { return s.key.Serialize() }
+
+func (s *secp256k1Signer) Public() Verifier {
+	return &secp256k1Verifier{key: s.key.PubKey()}
+}
+
+// Sign returns the deterministic RFC 6979 signature of the SHA-256 hash of
+// msg.
+func (s *secp256k1Signer) Sign(msg []byte) ([]byte, error) {
+	hash := sha256.Sum256(msg)
+	return secp256k1.Sign(s.key.ToECDSA(), hash[:])
+}
+
+func (v *secp256k1Verifier) Type() KeyType { return Secp256k1 }
+
+func (v *secp256k1Verifier) Bytes() []byte { return v.key.SerializeCompressed() }
+
+// Verify rejects high-S signatures, which secp256k1.Verify accepts.
+func (v *secp256k1Verifier) Verify(msg, sig []byte) bool {
+	if len(sig) != SignatureSize || new(big.Int).SetBytes(sig[32:]).Cmp(secp256k1HalfOrder) > 0 {
+		return false
+	}
+	hash := sha256.Sum256(msg)
+	return secp256k1.Verify(v.key.ToECDSA(), hash[:], sig)
+}
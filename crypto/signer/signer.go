@@ -0,0 +1,185 @@
+package signer
+
+import (
+	"errors"
+	"fmt"
+)
+
+// KeyType identifies the signature algorithm of a key.  It is the first byte
+// of every encoded key and signature, so it must never be renumbered.
+type KeyType byte
+
+// These constants define the supported key types.
+const (
+	Secp256k1 KeyType = iota + 1
+	P256
+	Ed25519
+)
+
+// SignatureSize is the size of a signature of every key type: the 32-byte
+// big endian r and s for ECDSA, and the standard Ed25519 signature.
+const SignatureSize = 64
+
+var keyTypeNames = map[KeyType]string{
+	Secp256k1: "secp256k1",
+	P256:      "p256",
+	Ed25519:   "ed25519",
+}
+
+// ErrUnknownKeyType is returned for a key type this package does not support.
+var ErrUnknownKeyType = errors.New("unknown key type")
+
+// String returns the name of the key type as accepted by ParseKeyType.
+func (t KeyType) String() string {
+	if name, ok := keyTypeNames[t]; ok {
+		return name
+	}
+	return fmt.Sprintf("KeyType(%d)", byte(t))
+}
+
+// ParseKeyType returns the key type with the name, which is one of
+// "secp256k1", "p256" or "ed25519".  It is meant for reading the key type
+// from configuration.
+func ParseKeyType(name string) (KeyType, error) {
+	for t, n := range keyTypeNames {
+		if n == name {
+			return t, nil
+		}
+	}
+	return 0, ErrUnknownKeyType
+}
+
+// Verifier is a public key that verifies signatures.
+type Verifier interface {
+	// Type returns the key type.
+	Type() KeyType
+
+	// Bytes returns the public key in the native format of its type: 33-byte
+	// SEC 1 compressed points for secp256k1 and P-256, and 32 bytes for
+	// Ed25519.
+	Bytes() []byte
+
+	// Verify reports whether sig is a valid signature of msg.  ECDSA
+	// signatures with s in the upper half of the curve order are rejected.
+	Verify(msg, sig []byte) bool
+}
+
+// Signer is a private key that signs messages.
+type Signer interface {
+	// Type returns the key type.
+	Type() KeyType
+
+	// Bytes returns the private key in the native format of its type: the
+	// 32-byte big endian scalar for secp256k1 and P-256, and the 32-byte
+	// seed for Ed25519.
+	Bytes() []byte
+
+	// Public returns the public key of the signer.
+	Public() Verifier
+
+	// Sign returns the SignatureSize-byte signature of msg.  ECDSA keys sign
+	// the SHA-256 hash of msg with s normalized to the lower half of the
+	// curve order, Ed25519 keys sign msg itself.
+	Sign(msg []byte) ([]byte, error)
+}
+
+// Generate returns a new random private key of the key type.
+func Generate(t KeyType) (Signer, error) {
+	switch t {
+	case Secp256k1:
+		return generateSecp256k1()
+	case P256:
+		return generateP256()
+	case Ed25519:
+		return generateEd25519()
+	}
+	return nil, ErrUnknownKeyType
+}
+
+// NewSigner returns the private key of the key type from its native format,
+// see Signer.Bytes.
+func NewSigner(t KeyType, privKey []byte) (Signer, error) {
+	switch t {
+	case Secp256k1:
+		return newSecp256k1Signer(privKey)
+	case P256:
+		return newP256Signer(privKey)
+	case Ed25519:
+		return newEd25519Signer(privKey)
+	}
+	return nil, ErrUnknownKeyType
+}
+
+// NewVerifier returns the public key of the key type from its native format,
+// see Verifier.Bytes.
+func NewVerifier(t KeyType, pubKey []byte) (Verifier, error) {
+	switch t {
+	case Secp256k1:
+		return newSecp256k1Verifier(pubKey)
+	case P256:
+		return newP256Verifier(pubKey)
+	case Ed25519:
+		return newEd25519Verifier(pubKey)
+	}
+	return nil, ErrUnknownKeyType
+}
+
+// EncodePublicKey returns the key type followed by the public key, so it can
+// be decoded without knowing the key type in advance.
+func EncodePublicKey(v Verifier) []byte {
+	return append([]byte{byte(v.Type())}, v.Bytes()...)
+}
+
+// DecodePublicKey decodes a public key encoded with EncodePublicKey.
+func DecodePublicKey(b []byte) (Verifier, error) {
+	if len(b) == 0 {
+		return nil, errors.New("empty public key")
+	}
+	return NewVerifier(KeyType(b[0]), b[1:])
+}
+
+// EncodePrivateKey returns the key type followed by the private key, so it
+// can be decoded without knowing the key type in advance.
+func EncodePrivateKey(s Signer) []byte {
+	return append([]byte{byte(s.Type())}, s.Bytes()...)
+}
+
+// DecodePrivateKey decodes a private key encoded with EncodePrivateKey.
+func DecodePrivateKey(b []byte) (Signer, error) {
+	if len(b) == 0 {
+		return nil, errors.New("empty private key")
+	}
+	return NewSigner(KeyType(b[0]), b[1:])
+}
+
+// EncodeSignature returns the key type followed by the signature.
+func EncodeSignature(t KeyType, sig []byte) []byte {
+	return append([]byte{byte(t)}, sig...)
+}
+
+// DecodeSignature decodes a signature encoded with EncodeSignature and
+// returns its key type and the signature.
+func DecodeSignature(b []byte) (KeyType, []byte, error) {
+	if len(b) != SignatureSize+1 {
+		return 0, nil, errors.New("invalid signature length")
+	}
+	t := KeyType(b[0])
+	if _, ok := keyTypeNames[t]; !ok {
+		return 0, nil, ErrUnknownKeyType
+	}
+	return t, b[1:], nil
+}
+
+// Verify reports whether the encoded signature of msg is valid for the
+// encoded public key, and that both are of the same key type.
+func Verify(pubKey, msg, sig []byte) bool {
+	v, err := DecodePublicKey(pubKey)
+	if err != nil {
+		return false
+	}
+	t, rawSig, err := DecodeSignature(sig)
+	if err != nil || t != v.Type() {
+		return false
+	}
+	return v.Verify(msg, rawSig)
+}
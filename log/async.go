@@ -0,0 +1,207 @@
+package log
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// OverflowPolicy 异步缓冲区满时的处理方式
+type OverflowPolicy int
+
+const (
+	OverflowDrop  OverflowPolicy = iota // 丢弃日志并计数
+	OverflowBlock                       // 阻塞直到缓冲区有空位
+)
+
+// AsyncConfig 异步写入配置
+type AsyncConfig struct {
+	BufferSize    int            // 缓冲区条数，默认8192
+	FlushInterval time.Duration  // 刷新间隔，默认100ms，缓冲区过半时立即刷新
+	Overflow      OverflowPolicy // 缓冲区满时的处理方式
+}
+
+// _dropped New创建的所有异步writer丢弃的日志条数
+var _dropped uint64
+
+// Dropped 返回异步写入因缓冲区满或写入失败丢弃的日志条数
+func Dropped() uint64 {
+	return atomic.LoadUint64(&_dropped)
+}
+
+var errAsyncClosed = errors.New("async writer is closed")
+
+// AsyncWriter 异步writer，日志先写入有界环形缓冲区，由后台goroutine批量写入下层writer
+type AsyncWriter struct {
+	ws       zapcore.WriteSyncer
+	overflow OverflowPolicy
+	dropped  uint64
+	counter  *uint64             // 额外累加丢弃条数，可为nil
+	errOut   zapcore.WriteSyncer // 后台写入失败时输出错误，默认标准错误
+
+	mu      sync.Mutex
+	notFull *sync.Cond
+	ring    [][]byte
+	head    int
+	n       int
+	closed  bool
+	batch   [][]byte // 仅由后台goroutine使用
+
+	wake    chan struct{}
+	syncReq chan chan error
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+// NewAsyncWriter 创建异步writer，不再使用时需调用Close
+func NewAsyncWriter(ws zapcore.WriteSyncer, cfg AsyncConfig) *AsyncWriter {
+	if cfg.BufferSize <= 0 {
+		cfg.BufferSize = 8192
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 100 * time.Millisecond
+	}
+	w := &AsyncWriter{
+		ws:       ws,
+		overflow: cfg.Overflow,
+		errOut:   zapcore.Lock(os.Stderr),
+		ring:     make([][]byte, cfg.BufferSize),
+		wake:     make(chan struct{}, 1),
+		syncReq:  make(chan chan error),
+		done:     make(chan struct{}),
+		stopped:  make(chan struct{}),
+	}
+	w.notFull = sync.NewCond(&w.mu)
+	go w.run(cfg.FlushInterval)
+	return w
+}
+
+// Write 将p复制到缓冲区，缓冲区满时按溢出策略丢弃或阻塞
+func (w *AsyncWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for w.n == len(w.ring) && !w.closed {
+		if w.overflow == OverflowDrop {
+			w.drop()
+			return len(p), nil
+		}
+		w.signal()
+		w.notFull.Wait()
+	}
+	if w.closed {
+		return 0, errAsyncClosed
+	}
+	w.ring[(w.head+w.n)%len(w.ring)] = append([]byte(nil), p...)
+	w.n++
+	if w.n >= len(w.ring)/2 {
+		w.signal()
+	}
+	return len(p), nil
+}
+
+// Sync 将缓冲区中的日志写入下层writer并同步
+func (w *AsyncWriter) Sync() error {
+	ch := make(chan error, 1)
+	select {
+	case w.syncReq <- ch:
+		return <-ch
+	case <-w.stopped:
+		return w.ws.Sync()
+	}
+}
+
+// Dropped 返回因缓冲区满或写入失败丢弃的日志条数
+func (w *AsyncWriter) Dropped() uint64 {
+	return atomic.LoadUint64(&w.dropped)
+}
+
+func (w *AsyncWriter) drop() {
+	atomic.AddUint64(&w.dropped, 1)
+	if w.counter != nil {
+		atomic.AddUint64(w.counter, 1)
+	}
+}
+
+// Close 写入缓冲区中的日志并停止后台goroutine
+func (w *AsyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	w.notFull.Broadcast()
+	w.mu.Unlock()
+	close(w.done)
+	<-w.stopped
+	return w.ws.Sync()
+}
+
+func (w *AsyncWriter) signal() {
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (w *AsyncWriter) run(interval time.Duration) {
+	defer close(w.stopped)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.report(w.flush())
+		case <-w.wake:
+			w.report(w.flush())
+		case ch := <-w.syncReq:
+			err := w.flush()
+			if syncErr := w.ws.Sync(); err == nil {
+				err = syncErr
+			}
+			ch <- err
+		case <-w.done:
+			w.report(w.flush())
+			return
+		}
+	}
+}
+
+// flush 取出缓冲区中的所有日志，逐条写入下层writer，避免合并后超出lumberjack的单次写入上限，
+// 并使每条日志进入写入时所在周期的文件；写入失败的日志计入丢弃条数，返回第一个错误
+func (w *AsyncWriter) flush() error {
+	w.mu.Lock()
+	for ; w.n > 0; w.n-- {
+		w.batch = append(w.batch, w.ring[w.head])
+		w.ring[w.head] = nil
+		w.head = (w.head + 1) % len(w.ring)
+	}
+	w.notFull.Broadcast()
+	w.mu.Unlock()
+
+	var err error
+	for i, p := range w.batch {
+		if _, writeErr := w.ws.Write(p); writeErr != nil {
+			w.drop()
+			if err == nil {
+				err = writeErr
+			}
+		}
+		w.batch[i] = nil
+	}
+	w.batch = w.batch[:0]
+	return err
+}
+
+// report 输出后台写入的错误，格式同zap写入失败时的输出
+func (w *AsyncWriter) report(err error) {
+	if err == nil {
+		return
+	}
+	fmt.Fprintf(w.errOut, "%v async write error: %v\n", time.Now(), err)
+	w.errOut.Sync()
+}
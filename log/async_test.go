@@ -0,0 +1,233 @@
+package log
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"gopkg.in/natefinch/lumberjack.v2"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gateWriter 在gate关闭前阻塞写入，开始写入时通知entered
+type gateWriter struct {
+	gate    chan struct{}
+	entered chan struct{}
+	mu      sync.Mutex
+	buf     bytes.Buffer
+	syncs   int
+}
+
+func newGateWriter(open bool) *gateWriter {
+	w := &gateWriter{gate: make(chan struct{}), entered: make(chan struct{}, 1)}
+	if open {
+		close(w.gate)
+	}
+	return w
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	select {
+	case w.entered <- struct{}{}:
+	default:
+	}
+	<-w.gate
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.Write(p)
+}
+
+func (w *gateWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.syncs++
+	return nil
+}
+
+func (w *gateWriter) String() string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.buf.String()
+}
+
+func TestAsyncWriterSync(t *testing.T) {
+	ws := newGateWriter(true)
+	w := NewAsyncWriter(ws, AsyncConfig{BufferSize: 1024, FlushInterval: time.Hour})
+	defer w.Close()
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(w, "line %d\n", i)
+	}
+	if err := w.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(ws.String(), "\n"); n != 100 {
+		t.Fatalf("%d lines written after Sync, want 100", n)
+	}
+	if ws.syncs != 1 {
+		t.Fatalf("underlying writer synced %d times, want 1", ws.syncs)
+	}
+}
+
+func TestAsyncWriterDrop(t *testing.T) {
+	ws := newGateWriter(false)
+	w := NewAsyncWriter(ws, AsyncConfig{BufferSize: 8, FlushInterval: time.Hour, Overflow: OverflowDrop})
+	// 写满一半时触发刷新，后台goroutine取出这4条后阻塞在写入上，缓冲区随后被填满
+	for i := 0; i < 4; i++ {
+		fmt.Fprintf(w, "line %d\n", i)
+	}
+	<-ws.entered
+	for i := 4; i < 20; i++ {
+		fmt.Fprintf(w, "line %d\n", i)
+	}
+	if w.Dropped() != 8 {
+		t.Fatalf("%d entries dropped, want 8", w.Dropped())
+	}
+	close(ws.gate)
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(ws.String(), "\n"); n != 12 {
+		t.Fatalf("%d lines written, want 12", n)
+	}
+}
+
+func TestAsyncWriterBlock(t *testing.T) {
+	ws := newGateWriter(false)
+	w := NewAsyncWriter(ws, AsyncConfig{BufferSize: 4, FlushInterval: time.Millisecond, Overflow: OverflowBlock})
+	done := make(chan struct{})
+	go func() {
+		for i := 0; i < 50; i++ {
+			fmt.Fprintf(w, "line %d\n", i)
+		}
+		close(done)
+	}()
+	select {
+	case <-done:
+		t.Fatal("writes did not block on a full buffer")
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(ws.gate)
+	<-done
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(ws.String(), "\n"); n != 50 || w.Dropped() != 0 {
+		t.Fatalf("%d lines written and %d dropped, want 50 and 0", n, w.Dropped())
+	}
+}
+
+func TestNewAsync(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := EnvDevelop.Config()
+	cfg.Dir = dir
+	cfg.Async = &AsyncConfig{FlushInterval: time.Hour}
+	logger := New(cfg)
+	defer Close(logger)
+	for i := 0; i < 10; i++ {
+		logger.Info("buffered entry")
+	}
+	if err := logger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(dir, "debug.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := strings.Count(string(b), "buffered entry"); n != 10 {
+		t.Fatalf("%d entries written after Sync, want 10", n)
+	}
+}
+
+func TestClose(t *testing.T) {
+	cfg := EnvDevelop.Config()
+	cfg.Dir = tempDir(t)
+	defer os.RemoveAll(cfg.Dir)
+	cfg.Async = &AsyncConfig{FlushInterval: time.Hour}
+	logger := New(cfg)
+	logger.Info("buffered entry")
+	if err := Close(logger.Named("child").With(zap.Int("n", 1))); err != nil {
+		t.Fatal(err)
+	}
+	b, err := ioutil.ReadFile(filepath.Join(cfg.Dir, "debug.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(b), "buffered entry") {
+		t.Fatalf("entry not written after Close: %q", b)
+	}
+	if err := Close(logger); err != nil {
+		t.Fatal(err)
+	}
+	if err := Close(zap.NewNop()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestAsyncWriterMaxSize(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	// 缓冲的日志合计超过MaxSize，逐条写入时由lumberjack切分而不是整批失败
+	lj := &lumberjack.Logger{Filename: filepath.Join(dir, "info.log"), MaxSize: 1}
+	defer lj.Close()
+	w := NewAsyncWriter(zapcore.AddSync(lj), AsyncConfig{BufferSize: 1024, FlushInterval: time.Hour})
+	line := strings.Repeat("x", 10*1024) + "\n"
+	for i := 0; i < 200; i++ {
+		io.WriteString(w, line)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if w.Dropped() != 0 {
+		t.Fatalf("%d entries dropped, want 0", w.Dropped())
+	}
+	var lines int
+	for _, name := range listDir(t, dir) {
+		b, err := ioutil.ReadFile(filepath.Join(dir, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		lines += strings.Count(string(b), "\n")
+	}
+	if lines != 200 {
+		t.Fatalf("%d lines written, want 200", lines)
+	}
+}
+
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) { return 0, errors.New("disk full") }
+
+func (failWriter) Sync() error { return nil }
+
+func TestAsyncWriterWriteError(t *testing.T) {
+	errOut := newGateWriter(true)
+	w := NewAsyncWriter(failWriter{}, AsyncConfig{FlushInterval: time.Hour})
+	w.errOut = errOut
+	io.WriteString(w, "a\n")
+	io.WriteString(w, "b\n")
+	if err := w.Sync(); err == nil {
+		t.Fatal("expected error from Sync")
+	}
+	io.WriteString(w, "c\n")
+	w.Close()
+	if w.Dropped() != 3 {
+		t.Fatalf("%d entries dropped, want 3", w.Dropped())
+	}
+	if !strings.Contains(errOut.String(), "async write error: disk full") {
+		t.Fatalf("error not reported: %q", errOut.String())
+	}
+}
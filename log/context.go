@@ -0,0 +1,134 @@
+package log
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"go.uber.org/zap"
+	"net/http"
+	"strings"
+)
+
+const (
+	HeaderRequestID   = "X-Request-ID"
+	HeaderTraceParent = "traceparent"
+
+	FieldTraceID   = "trace_id"
+	FieldSpanID    = "span_id"
+	FieldRequestID = "request_id"
+)
+
+type ctxKey int
+
+const (
+	fieldsKey ctxKey = iota
+	requestIDKey
+	traceKey
+)
+
+type trace struct {
+	traceID string
+	spanID  string
+}
+
+// WithContext 将字段存入context，Ctx(ctx)输出日志时自动带上
+func WithContext(ctx context.Context, fields ...zap.Field) context.Context {
+	old, _ := ctx.Value(fieldsKey).([]zap.Field)
+	merged := make([]zap.Field, 0, len(old)+len(fields))
+	merged = append(merged, old...)
+	merged = append(merged, fields...)
+	return context.WithValue(ctx, fieldsKey, merged)
+}
+
+// WithRequestID 将请求ID存入context
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey, requestID)
+}
+
+// RequestID 返回context中的请求ID
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey).(string)
+	return id
+}
+
+// WithTrace 将链路追踪的trace id和span id存入context
+func WithTrace(ctx context.Context, traceID, spanID string) context.Context {
+	return context.WithValue(ctx, traceKey, trace{traceID: traceID, spanID: spanID})
+}
+
+// Trace 返回context中的trace id和span id
+func Trace(ctx context.Context) (traceID, spanID string) {
+	t, _ := ctx.Value(traceKey).(trace)
+	return t.traceID, t.spanID
+}
+
+// Ctx 返回带有context中trace_id、span_id、request_id及WithContext字段的logger
+func Ctx(ctx context.Context) *zap.Logger {
+	logger := L()
+	if fields := ContextFields(ctx); len(fields) > 0 {
+		return logger.With(fields...)
+	}
+	return logger
+}
+
+// ContextFields 返回context中的trace_id、span_id、request_id及WithContext字段
+func ContextFields(ctx context.Context) []zap.Field {
+	if ctx == nil {
+		return nil
+	}
+	var fields []zap.Field
+	if traceID, spanID := Trace(ctx); traceID != "" {
+		fields = append(fields, zap.String(FieldTraceID, traceID), zap.String(FieldSpanID, spanID))
+	}
+	if requestID := RequestID(ctx); requestID != "" {
+		fields = append(fields, zap.String(FieldRequestID, requestID))
+	}
+	if ctxFields, ok := ctx.Value(fieldsKey).([]zap.Field); ok {
+		fields = append(fields, ctxFields...)
+	}
+	return fields
+}
+
+// RequestIDMiddleware 沿用请求头X-Request-ID或生成新的请求ID，写入响应头并存入context，
+// 同时解析W3C traceparent请求头中的trace id和span id
+func RequestIDMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := r.Context()
+		requestID := r.Header.Get(HeaderRequestID)
+		if requestID == "" {
+			requestID = newRequestID()
+		}
+		w.Header().Set(HeaderRequestID, requestID)
+		ctx = WithRequestID(ctx, requestID)
+		if traceID, spanID, ok := parseTraceParent(r.Header.Get(HeaderTraceParent)); ok {
+			ctx = WithTrace(ctx, traceID, spanID)
+		}
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// parseTraceParent 解析 version-traceid-spanid-flags 格式的traceparent
+func parseTraceParent(s string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(s, "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return "", "", false
+	}
+	if _, err := hex.DecodeString(parts[1]); err != nil {
+		return "", "", false
+	}
+	if _, err := hex.DecodeString(parts[2]); err != nil {
+		return "", "", false
+	}
+	if strings.Trim(parts[1], "0") == "" || strings.Trim(parts[2], "0") == "" {
+		return "", "", false
+	}
+	return parts[1], parts[2], true
+}
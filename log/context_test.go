@@ -0,0 +1,95 @@
+package log
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// observe 将全局logger替换为记录日志的logger，返回的函数恢复原logger
+func observe() (*observer.ObservedLogs, func()) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	old := current()
+	ReplaceGlobal(zap.New(core, zap.AddCaller()))
+	return logs, func() { ReplaceGlobal(old) }
+}
+
+func TestCtx(t *testing.T) {
+	logs, restore := observe()
+	defer restore()
+
+	ctx := WithContext(context.Background(), zap.String("user", "alice"))
+	ctx = WithContext(ctx, zap.Int("attempt", 2))
+	ctx = WithRequestID(ctx, "req-1")
+	ctx = WithTrace(ctx, "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7")
+	Ctx(ctx).Info("hello")
+	Ctx(context.Background()).Info("plain")
+
+	entries := logs.All()
+	if len(entries) != 2 {
+		t.Fatalf("got %d entries, want 2", len(entries))
+	}
+	fields := entries[0].ContextMap()
+	want := map[string]interface{}{
+		FieldTraceID:   "4bf92f3577b34da6a3ce929d0e0e4736",
+		FieldSpanID:    "00f067aa0ba902b7",
+		FieldRequestID: "req-1",
+		"user":         "alice",
+		"attempt":      int64(2),
+	}
+	for k, v := range want {
+		if fields[k] != v {
+			t.Errorf("field %s = %v, want %v", k, fields[k], v)
+		}
+	}
+	if !strings.HasSuffix(entries[0].Caller.File, "context_test.go") {
+		t.Errorf("caller %s, want context_test.go", entries[0].Caller.File)
+	}
+	if len(entries[1].Context) != 0 {
+		t.Errorf("plain entry has fields %v", entries[1].ContextMap())
+	}
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	logs, restore := observe()
+	defer restore()
+	handler := RequestIDMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		Ctx(r.Context()).Info("handled")
+	}))
+
+	// 沿用请求中的请求ID和traceparent
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderRequestID, "abc")
+	req.Header.Set(HeaderTraceParent, "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get(HeaderRequestID); got != "abc" {
+		t.Fatalf("response request id %q, want abc", got)
+	}
+	fields := logs.All()[0].ContextMap()
+	if fields[FieldRequestID] != "abc" || fields[FieldTraceID] != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+
+	// 生成新的请求ID，忽略无效的traceparent
+	req = httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set(HeaderTraceParent, "00-00000000000000000000000000000000-00f067aa0ba902b7-01")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	id := rec.Header().Get(HeaderRequestID)
+	if len(id) != 32 {
+		t.Fatalf("generated request id %q", id)
+	}
+	fields = logs.All()[1].ContextMap()
+	if fields[FieldRequestID] != id {
+		t.Fatalf("logged request id %v, want %s", fields[FieldRequestID], id)
+	}
+	if _, ok := fields[FieldTraceID]; ok {
+		t.Fatal("invalid traceparent logged")
+	}
+}
@@ -0,0 +1,200 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HTTPSink 将日志批量以换行分隔的JSON通过HTTP POST输出，失败时按指数退避重试，重试后仍失败的计入SinkFailed
+type HTTPSink struct {
+	URL           string
+	Header        http.Header
+	BatchSize     int           // 每批条数，默认100
+	FlushInterval time.Duration // 未满一批时的发送间隔，默认1s
+	MaxRetries    int           // 最大重试次数，默认3
+	Backoff       time.Duration // 首次重试间隔，之后每次翻倍，默认100ms
+	MaxPending    int           // 待发送的最大条数，超出时丢弃并计入SinkDropped，默认BatchSize的10倍
+	Client        *http.Client  // 默认超时10s
+}
+
+func (s HTTPSink) Core(enc zapcore.Encoder) (zapcore.Core, func() error) {
+	w := newHTTPWriter(s)
+	return zapcore.NewCore(enc, w, zapcore.DebugLevel), w.Close
+}
+
+type httpWriter struct {
+	sink HTTPSink
+
+	mu      sync.Mutex
+	pending bytes.Buffer
+	n       int
+
+	wake    chan struct{}
+	syncReq chan chan error
+	once    sync.Once
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func newHTTPWriter(s HTTPSink) *httpWriter {
+	if s.BatchSize <= 0 {
+		s.BatchSize = 100
+	}
+	if s.FlushInterval <= 0 {
+		s.FlushInterval = time.Second
+	}
+	if s.MaxRetries <= 0 {
+		s.MaxRetries = 3
+	}
+	if s.Backoff <= 0 {
+		s.Backoff = 100 * time.Millisecond
+	}
+	if s.MaxPending <= 0 {
+		s.MaxPending = 10 * s.BatchSize
+	}
+	if s.Client == nil {
+		s.Client = &http.Client{Timeout: 10 * time.Second}
+	}
+	w := &httpWriter{
+		sink:    s,
+		wake:    make(chan struct{}, 1),
+		syncReq: make(chan chan error),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+func (w *httpWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.n >= w.sink.MaxPending {
+		atomic.AddUint64(&_sinkDropped, 1)
+		return len(p), nil
+	}
+	w.pending.Write(p)
+	w.n++
+	if w.n >= w.sink.BatchSize {
+		select {
+		case w.wake <- struct{}{}:
+		default:
+		}
+	}
+	return len(p), nil
+}
+
+// Sync 发送所有待发送的日志
+func (w *httpWriter) Sync() error {
+	ch := make(chan error, 1)
+	select {
+	case w.syncReq <- ch:
+		return <-ch
+	case <-w.stopped:
+		return nil
+	}
+}
+
+// Close 发送所有待发送的日志并停止后台goroutine
+func (w *httpWriter) Close() error {
+	var err error
+	w.once.Do(func() {
+		close(w.done)
+		<-w.stopped
+		err = w.flush()
+	})
+	return err
+}
+
+func (w *httpWriter) run() {
+	defer close(w.stopped)
+	ticker := time.NewTicker(w.sink.FlushInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			w.flush()
+		case <-w.wake:
+			w.flush()
+		case ch := <-w.syncReq:
+			ch <- w.flush()
+		case <-w.done:
+			return
+		}
+	}
+}
+
+// flush 按BatchSize分批发送待发送的日志
+func (w *httpWriter) flush() error {
+	w.mu.Lock()
+	data := append([]byte(nil), w.pending.Bytes()...)
+	w.pending.Reset()
+	w.n = 0
+	w.mu.Unlock()
+
+	var err error
+	for len(data) > 0 {
+		end, lines := 0, 0
+		for end < len(data) && lines < w.sink.BatchSize {
+			i := bytes.IndexByte(data[end:], '\n')
+			if i < 0 {
+				end = len(data)
+				break
+			}
+			end += i + 1
+			lines++
+		}
+		if postErr := w.post(data[:end]); postErr != nil {
+			atomic.AddUint64(&_sinkFailed, uint64(lines))
+			err = postErr
+		}
+		data = data[end:]
+	}
+	return err
+}
+
+// post 发送一批日志，网络错误、429及5xx响应时重试
+func (w *httpWriter) post(batch []byte) error {
+	backoff := w.sink.Backoff
+	var err error
+	for attempt := 0; attempt <= w.sink.MaxRetries; attempt++ {
+		if attempt > 0 {
+			time.Sleep(backoff)
+			backoff *= 2
+		}
+		var retry bool
+		if retry, err = w.send(batch); err == nil || !retry {
+			return err
+		}
+	}
+	return err
+}
+
+func (w *httpWriter) send(batch []byte) (retry bool, err error) {
+	req, err := http.NewRequest(http.MethodPost, w.sink.URL, bytes.NewReader(batch))
+	if err != nil {
+		return false, err
+	}
+	for k, v := range w.sink.Header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", "application/x-ndjson")
+	resp, err := w.sink.Client.Do(req)
+	if err != nil {
+		return true, err
+	}
+	io.Copy(ioutil.Discard, resp.Body)
+	resp.Body.Close()
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		return false, nil
+	}
+	err = fmt.Errorf("log sink responded %s", resp.Status)
+	return resp.StatusCode == http.StatusTooManyRequests || resp.StatusCode >= 500, err
+}
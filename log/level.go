@@ -0,0 +1,241 @@
+package log
+
+import (
+	"encoding/json"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// Levels 运行时日志级别，包括默认级别及按模块覆盖的级别，New创建的每个logger各有一份
+type Levels struct {
+	level zap.AtomicLevel
+
+	mu      sync.Mutex
+	modules atomic.Value // map[string]zapcore.Level，写时复制
+	min     int32        // 默认及所有模块级别中的最低级别，修改级别时更新
+}
+
+// NewLevels 创建默认级别为l、按modules覆盖模块级别的Levels
+func NewLevels(l zapcore.Level, modules map[string]zapcore.Level) *Levels {
+	lv := &Levels{level: zap.NewAtomicLevelAt(l)}
+	copied := make(map[string]zapcore.Level, len(modules))
+	for k, v := range modules {
+		copied[k] = v
+	}
+	lv.modules.Store(copied)
+	lv.updateMin()
+	return lv
+}
+
+// LevelsOf 返回New创建的logger的Levels，其他logger返回nil
+func LevelsOf(logger *zap.Logger) *Levels {
+	if c, ok := logger.Core().(levelCore); ok {
+		return c.lv
+	}
+	return nil
+}
+
+// SetLevel 修改默认级别
+func (lv *Levels) SetLevel(l zapcore.Level) {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+	lv.level.SetLevel(l)
+	lv.updateMin()
+}
+
+// Level 返回默认级别
+func (lv *Levels) Level() zapcore.Level {
+	return lv.level.Level()
+}
+
+// SetModuleLevel 设置模块的日志级别，覆盖默认级别，对名称为module及module.xxx的logger生效
+func (lv *Levels) SetModuleLevel(module string, l zapcore.Level) {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+	modules := lv.ModuleLevels()
+	modules[module] = l
+	lv.modules.Store(modules)
+	lv.updateMin()
+}
+
+// ClearModuleLevel 取消模块的日志级别覆盖
+func (lv *Levels) ClearModuleLevel(module string) {
+	lv.mu.Lock()
+	defer lv.mu.Unlock()
+	modules := lv.ModuleLevels()
+	delete(modules, module)
+	lv.modules.Store(modules)
+	lv.updateMin()
+}
+
+// ModuleLevels 返回所有模块的日志级别覆盖
+func (lv *Levels) ModuleLevels() map[string]zapcore.Level {
+	old := lv.modules.Load().(map[string]zapcore.Level)
+	modules := make(map[string]zapcore.Level, len(old)+1)
+	for k, v := range old {
+		modules[k] = v
+	}
+	return modules
+}
+
+// levelFor 返回名称为name的logger生效的日志级别，使用最接近的模块覆盖
+func (lv *Levels) levelFor(name string) zapcore.Level {
+	modules := lv.modules.Load().(map[string]zapcore.Level)
+	if len(modules) > 0 {
+		for name != "" {
+			if l, ok := modules[name]; ok {
+				return l
+			}
+			i := strings.LastIndexByte(name, '.')
+			if i < 0 {
+				break
+			}
+			name = name[:i]
+		}
+	}
+	return lv.level.Level()
+}
+
+// minLevel 返回默认及所有模块级别中的最低级别，在每次日志调用时使用
+func (lv *Levels) minLevel() zapcore.Level {
+	return zapcore.Level(atomic.LoadInt32(&lv.min))
+}
+
+// updateMin 重新计算最低级别，需持有mu
+func (lv *Levels) updateMin() {
+	min := lv.level.Level()
+	for _, l := range lv.modules.Load().(map[string]zapcore.Level) {
+		if l < min {
+			min = l
+		}
+	}
+	atomic.StoreInt32(&lv.min, int32(min))
+}
+
+// SetLevel 修改全局logger的默认级别
+func SetLevel(l zapcore.Level) {
+	globalLevels().SetLevel(l)
+}
+
+// GetLevel 返回全局logger的默认级别
+func GetLevel() zapcore.Level {
+	return globalLevels().Level()
+}
+
+// SetModuleLevel 设置全局logger的模块级别
+func SetModuleLevel(module string, l zapcore.Level) {
+	globalLevels().SetModuleLevel(module, l)
+}
+
+// ClearModuleLevel 取消全局logger的模块级别覆盖
+func ClearModuleLevel(module string) {
+	globalLevels().ClearModuleLevel(module)
+}
+
+// ModuleLevels 返回全局logger的所有模块级别覆盖
+func ModuleLevels() map[string]zapcore.Level {
+	return globalLevels().ModuleLevels()
+}
+
+// levelCore 按Levels过滤日志
+type levelCore struct {
+	zapcore.Core
+	lv      *Levels
+	closers *closers // New创建的logger持有的资源，其他logger为nil
+}
+
+func (c levelCore) Enabled(l zapcore.Level) bool {
+	return l >= c.lv.minLevel() && c.Core.Enabled(l)
+}
+
+func (c levelCore) With(fields []zapcore.Field) zapcore.Core {
+	return levelCore{Core: c.Core.With(fields), lv: c.lv, closers: c.closers}
+}
+
+func (c levelCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if ent.Level < c.lv.levelFor(ent.LoggerName) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
+
+type levelRequest struct {
+	Module string `json:"module,omitempty"`
+	Level  string `json:"level"`
+}
+
+type levelResponse struct {
+	Level   string            `json:"level"`
+	Modules map[string]string `json:"modules,omitempty"`
+	Error   string            `json:"error,omitempty"`
+}
+
+// LevelHandler 查看及修改全局logger日志级别的http.Handler，ReplaceGlobal后作用于新的全局logger
+//
+// GET 返回 {"level":"info","modules":{"mongo":"debug"}}
+// PUT {"level":"debug"} 修改默认级别
+// PUT {"module":"mongo","level":"debug"} 修改模块级别，level为空时取消覆盖
+func LevelHandler() http.Handler {
+	return levelHandler(globalLevels)
+}
+
+// Handler 查看及修改lv的http.Handler，格式同LevelHandler
+func (lv *Levels) Handler() http.Handler {
+	return levelHandler(func() *Levels { return lv })
+}
+
+func levelHandler(levels func() *Levels) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lv := levels()
+		w.Header().Set("Content-Type", "application/json")
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut:
+			var req levelRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				writeLevelError(w, err.Error())
+				return
+			}
+			if req.Level == "" {
+				// zap将空字符串解析为info，不指定模块时不能用于取消覆盖
+				if req.Module == "" {
+					writeLevelError(w, "level is required")
+					return
+				}
+				lv.ClearModuleLevel(req.Module)
+				break
+			}
+			var l zapcore.Level
+			if err := l.UnmarshalText([]byte(req.Level)); err != nil {
+				writeLevelError(w, err.Error())
+				return
+			}
+			if req.Module != "" {
+				lv.SetModuleLevel(req.Module, l)
+			} else {
+				lv.SetLevel(l)
+			}
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			json.NewEncoder(w).Encode(levelResponse{Error: "only GET and PUT are supported"})
+			return
+		}
+		resp := levelResponse{Level: lv.Level().String()}
+		if modules := lv.ModuleLevels(); len(modules) > 0 {
+			resp.Modules = make(map[string]string, len(modules))
+			for k, v := range modules {
+				resp.Modules[k] = v.String()
+			}
+		}
+		json.NewEncoder(w).Encode(resp)
+	})
+}
+
+func writeLevelError(w http.ResponseWriter, msg string) {
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(levelResponse{Error: msg})
+}
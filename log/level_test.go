@@ -0,0 +1,145 @@
+package log
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestLevel(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	lv := NewLevels(zapcore.DebugLevel, nil)
+	logger := zap.New(levelCore{Core: core, lv: lv})
+	mongo := logger.Named("mongo")
+	cursor := mongo.Named("cursor")
+	if LevelsOf(cursor) != lv {
+		t.Fatal("LevelsOf does not return the logger's levels")
+	}
+
+	lv.SetLevel(zapcore.InfoLevel)
+	logger.Debug("hidden")
+	mongo.Debug("hidden")
+	logger.Info("shown")
+
+	// 模块级别覆盖全局级别，对子模块同样生效
+	lv.SetModuleLevel("mongo", zapcore.DebugLevel)
+	logger.Debug("hidden")
+	mongo.Debug("shown")
+	cursor.Debug("shown")
+
+	lv.SetModuleLevel("mongo", zapcore.ErrorLevel)
+	mongo.Warn("hidden")
+	logger.Warn("shown")
+
+	lv.ClearModuleLevel("mongo")
+	mongo.Warn("shown")
+
+	for _, e := range logs.All() {
+		if e.Message != "shown" {
+			t.Errorf("%s %s entry of %q logged", e.Level, e.Message, e.LoggerName)
+		}
+	}
+	if n := logs.Len(); n != 5 {
+		t.Fatalf("%d entries logged, want 5", n)
+	}
+}
+
+// TestNewLevels New创建的logger各自持有级别，只有全局logger的级别受包级函数控制
+func TestNewLevels(t *testing.T) {
+	_, restore := observe()
+	defer restore()
+	SetLevel(zapcore.WarnLevel)
+	SetModuleLevel("mongo", zapcore.ErrorLevel)
+
+	cfg := EnvDevelop.Config()
+	cfg.Dir = tempDir(t)
+	defer os.RemoveAll(cfg.Dir)
+	cfg.Modules = map[string]zapcore.Level{"mongo": zapcore.InfoLevel}
+	logger := New(cfg)
+	if GetLevel() != zapcore.WarnLevel || ModuleLevels()["mongo"] != zapcore.ErrorLevel {
+		t.Fatalf("New changed the global levels: %s %v", GetLevel(), ModuleLevels())
+	}
+	lv := LevelsOf(logger)
+	if lv == nil || lv.Level() != zapcore.DebugLevel || lv.ModuleLevels()["mongo"] != zapcore.InfoLevel {
+		t.Fatalf("levels of New logger: %v", lv)
+	}
+
+	// 替换全局logger后，包级函数控制新的logger
+	if err := ReplaceGlobal(logger); err != nil {
+		t.Fatal(err)
+	}
+	SetLevel(zapcore.ErrorLevel)
+	if lv.Level() != zapcore.ErrorLevel {
+		t.Fatalf("SetLevel did not reach the replaced logger: %s", lv.Level())
+	}
+}
+
+func TestLevelsMin(t *testing.T) {
+	lv := NewLevels(zapcore.WarnLevel, map[string]zapcore.Level{"mongo": zapcore.InfoLevel})
+	for _, c := range []struct {
+		change func()
+		want   zapcore.Level
+	}{
+		{func() {}, zapcore.InfoLevel},
+		{func() { lv.SetModuleLevel("mysql", zapcore.DebugLevel) }, zapcore.DebugLevel},
+		{func() { lv.ClearModuleLevel("mysql") }, zapcore.InfoLevel},
+		{func() { lv.ClearModuleLevel("mongo") }, zapcore.WarnLevel},
+		{func() { lv.SetLevel(zapcore.ErrorLevel) }, zapcore.ErrorLevel},
+	} {
+		c.change()
+		if got := lv.minLevel(); got != c.want {
+			t.Fatalf("minLevel = %s, want %s", got, c.want)
+		}
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	_, restore := observe()
+	defer restore()
+	SetLevel(zapcore.InfoLevel)
+
+	handler := LevelHandler()
+	serve := func(method, body string) (int, string) {
+		req := httptest.NewRequest(method, "/log/level", strings.NewReader(body))
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code, strings.TrimSpace(rec.Body.String())
+	}
+
+	if code, body := serve(http.MethodGet, ""); code != http.StatusOK || body != `{"level":"info"}` {
+		t.Fatalf("GET: %d %s", code, body)
+	}
+	if code, body := serve(http.MethodPut, `{"level":"debug"}`); code != http.StatusOK || body != `{"level":"debug"}` {
+		t.Fatalf("PUT level: %d %s", code, body)
+	}
+	if GetLevel() != zapcore.DebugLevel {
+		t.Fatalf("level %s, want debug", GetLevel())
+	}
+	if code, body := serve(http.MethodPut, `{"module":"mongo","level":"error"}`); code != http.StatusOK ||
+		body != `{"level":"debug","modules":{"mongo":"error"}}` {
+		t.Fatalf("PUT module level: %d %s", code, body)
+	}
+	if code, body := serve(http.MethodPut, `{"module":"mongo","level":""}`); code != http.StatusOK || body != `{"level":"debug"}` {
+		t.Fatalf("PUT clear module level: %d %s", code, body)
+	}
+	if code, _ := serve(http.MethodPut, `{"level":"verbose"}`); code != http.StatusBadRequest {
+		t.Fatalf("PUT invalid level: %d", code)
+	}
+	// 缺少level时不能把级别重置为info
+	for _, body := range []string{`{}`, `{"level":""}`} {
+		if code, _ := serve(http.MethodPut, body); code != http.StatusBadRequest {
+			t.Fatalf("PUT %s: %d, want 400", body, code)
+		}
+		if GetLevel() != zapcore.DebugLevel {
+			t.Fatalf("PUT %s changed the level to %s", body, GetLevel())
+		}
+	}
+	if code, _ := serve(http.MethodPost, `{"level":"info"}`); code != http.StatusMethodNotAllowed {
+		t.Fatalf("POST: %d", code)
+	}
+}
@@ -6,13 +6,20 @@ import (
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
+	"os"
+	"path/filepath"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-var _logger *zap.Logger
+// _global 全局logger及其级别，*global，可通过ReplaceGlobal多次替换
+var _global atomic.Value
 
-var once sync.Once
+type global struct {
+	logger *zap.Logger
+	levels *Levels // SetLevel等包级函数修改的级别
+}
 
 type Env string
 
@@ -29,7 +36,41 @@ const (
 )
 
 func Sync() error {
-	return _logger.Sync()
+	return current().Sync()
+}
+
+// Close 写入logger缓冲区中的日志，并停止New为其启动的后台goroutine，之后不应再使用该logger；
+// 不是由New创建的logger直接返回nil。替换全局logger后，被替换的logger不再使用时需调用Close
+func Close(logger *zap.Logger) error {
+	if c, ok := logger.Core().(levelCore); ok {
+		return c.closers.close()
+	}
+	return nil
+}
+
+// closers New创建的logger持有的异步writer等资源，按创建的逆序关闭
+type closers struct {
+	once sync.Once
+	fns  []func() error
+	err  error
+}
+
+func (c *closers) add(fn func() error) {
+	c.fns = append(c.fns, fn)
+}
+
+func (c *closers) close() error {
+	if c == nil {
+		return nil
+	}
+	c.once.Do(func() {
+		for i := len(c.fns) - 1; i >= 0; i-- {
+			if err := c.fns[i](); err != nil && c.err == nil {
+				c.err = err
+			}
+		}
+	})
+	return c.err
 }
 
 func (env gteDebug) Enabled(l zapcore.Level) bool {
@@ -65,65 +106,177 @@ func (env gteError) String() string {
 }
 
 func init() {
-	_logger = New(EnvDevelop)
+	ReplaceGlobal(New(EnvDevelop.Config()))
 }
 
-func New(env Env) *zap.Logger {
-	var enablers = make([]zapcore.LevelEnabler, 0, 3)
-	var cores = make([]zapcore.Core, 0, 3)
+// Config 日志配置
+type Config struct {
+	Level      zapcore.Level            // 最低输出级别，运行时可通过LevelsOf修改，作为全局logger时可通过SetLevel修改
+	Modules    map[string]zapcore.Level // 按模块覆盖的日志级别
+	Dir        string                   // 日志目录，默认 ./log
+	FileName   string                   // 文件名模式，%s 替换为级别名，默认 %s.log
+	MaxSize    int                      // 文件大小，单位：M
+	MaxBackups int                      // 备份数量
+	MaxAge     int                      // 日志保留天数，按时间切分时Rotation.MaxAge为0则使用该值
+	Compress   bool                     // 是否压缩，按时间切分时与Rotation.Compress任一为true即压缩
+	Rotation   *RotationConfig          // 按时间切分配置，为nil时按MaxSize切分，MaxSize、MaxBackups不生效
+	Encoder    string                   // 编码格式：console 或 json，默认 console
+	Stdout     bool                     // 是否同时输出到标准输出
+	MaskRules  []MaskRule               // 脱敏规则，为空时不脱敏，可使用DefaultMaskRules
+	Async      *AsyncConfig             // 异步写入配置，为nil时同步写入
+	Sampling   *SamplingConfig          // 采样及限流配置，为nil时不采样
+	Sinks      []Sink                   // 远程输出，与文件输出并行，如SyslogSink、TCPSink、HTTPSink，各自异步发送，不受Async影响
+}
+
+const (
+	EncoderConsole = "console"
+	EncoderJSON    = "json"
+)
+
+// Config 返回环境对应的预设配置
+func (env Env) Config() Config {
+	cfg := Config{
+		Level:      zapcore.DebugLevel,
+		Dir:        "./log",
+		FileName:   "%s.log",
+		MaxSize:    100,
+		MaxBackups: 50,
+		MaxAge:     365,
+		Compress:   true,
+		Encoder:    EncoderConsole,
+	}
 	// 生产环境不输出debug
-	switch env {
-	case EnvProduct:
-		enablers = []zapcore.LevelEnabler{gteInfo{}, eqWarn{}, gteError{}}
-	default:
-		enablers = []zapcore.LevelEnabler{gteDebug{}, eqWarn{}, gteError{}}
+	if env == EnvProduct {
+		cfg.Level = zapcore.InfoLevel
+	}
+	return cfg
+}
+
+func New(cfg Config) *zap.Logger {
+	if cfg.Dir == "" {
+		cfg.Dir = "./log"
+	}
+	if cfg.FileName == "" {
+		cfg.FileName = "%s.log"
+	}
+	lv := NewLevels(cfg.Level, cfg.Modules)
+	cs := &closers{}
+	// 级别由levelCore在运行时过滤，debug文件按初始级别命名，生产环境为info
+	var enablers = []zapcore.LevelEnabler{gteDebug{}, eqWarn{}, gteError{}}
+	var names = []fmt.Stringer{gteDebug{}, eqWarn{}, gteError{}}
+	if cfg.Level > zapcore.DebugLevel {
+		names[0] = gteInfo{}
+	}
+	var cores = make([]zapcore.Core, 0, 4+len(cfg.Sinks))
+	for i := range enablers {
+		cores = append(cores, newCore(cfg, names[i], enablers[i], cs))
+	}
+	if cfg.Stdout {
+		cores = append(cores, zapcore.NewCore(newEncoder(cfg), newWriter(cfg, zapcore.Lock(os.Stdout), cs), enablers[0]))
+	}
+	// 远程输出统一使用JSON编码
+	sinkCfg := cfg
+	sinkCfg.Encoder = EncoderJSON
+	for _, sink := range cfg.Sinks {
+		sinkCore, closeSink := sink.Core(newEncoder(sinkCfg))
+		cores = append(cores, sinkCore)
+		cs.add(closeSink)
 	}
-	for i, _ := range enablers {
-		cores = append(cores, newCore(enablers[i]))
+	core := zapcore.NewTee(cores...)
+	if cfg.Sampling != nil {
+		core = newSampleCore(core, *cfg.Sampling, cs)
 	}
-	logger := zap.New(zapcore.NewTee(cores...), zap.AddCaller(), zap.AddCallerSkip(1), zap.AddStacktrace(zap.DPanicLevel))
+	logger := zap.New(levelCore{Core: core, lv: lv, closers: cs}, zap.AddCaller(), zap.AddStacktrace(zap.DPanicLevel))
 	return logger
 }
 
+// ReplaceGlobal 替换全局logger，可多次调用，L、Named返回的logger及包级函数均使用替换后的logger，
+// logger按直接调用设置caller，包级函数及Named的方法在内部跳过自身的栈帧，
+// SetLevel、LevelHandler等修改替换后logger的级别；不是由New创建的logger会加上一层默认级别为debug的级别过滤
 func ReplaceGlobal(logger *zap.Logger) error {
-	var isDo bool
-	once.Do(func() {
-		_logger = logger
-		isDo = true
-	})
-	if !isDo {
-		return errors.New("can not replace once more")
+	if logger == nil {
+		return errors.New("logger is nil")
+	}
+	lv := LevelsOf(logger)
+	if lv == nil {
+		lv = NewLevels(zapcore.DebugLevel, nil)
+		logger = logger.WithOptions(zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return levelCore{Core: core, lv: lv}
+		}))
 	}
+	_global.Store(&global{logger: logger, levels: lv})
 	return nil
 }
 
+func current() *zap.Logger {
+	return _global.Load().(*global).logger
+}
+
+func globalLevels() *Levels {
+	return _global.Load().(*global).levels
+}
+
 func Debug(msg string, field ...zap.Field) {
-	_logger.Debug(msg, field...)
+	root.get().logger.Debug(msg, field...)
 }
 
 func Info(msg string, field ...zap.Field) {
-	_logger.Info(msg, field...)
+	root.get().logger.Info(msg, field...)
 }
 
 func Warn(msg string, field ...zap.Field) {
-	_logger.Warn(msg, field...)
+	root.get().logger.Warn(msg, field...)
 }
 
 func Error(msg string, field ...zap.Field) {
-	_logger.Error(msg, field...)
+	root.get().logger.Error(msg, field...)
+}
+
+func newCore(cfg Config, name fmt.Stringer, enabler zapcore.LevelEnabler, cs *closers) zapcore.Core {
+	filename := filepath.Join(cfg.Dir, fmt.Sprintf(cfg.FileName, name))
+	var ws zapcore.WriteSyncer
+	if cfg.Rotation != nil {
+		rotation := *cfg.Rotation
+		if rotation.MaxAge == 0 {
+			rotation.MaxAge = cfg.MaxAge
+		}
+		rotation.Compress = rotation.Compress || cfg.Compress
+		w := NewTimeRotateWriter(filename, rotation)
+		cs.add(w.Close)
+		ws = w
+	} else {
+		ws = zapcore.AddSync(&lumberjack.Logger{
+			Filename:   filename,
+			MaxSize:    cfg.MaxSize,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAge,
+			Compress:   cfg.Compress,
+		})
+	}
+	return zapcore.NewCore(newEncoder(cfg), newWriter(cfg, ws, cs), enabler)
+}
+
+func newWriter(cfg Config, ws zapcore.WriteSyncer, cs *closers) zapcore.WriteSyncer {
+	if cfg.Async == nil {
+		return ws
+	}
+	w := NewAsyncWriter(ws, *cfg.Async)
+	w.counter = &_dropped
+	cs.add(w.Close)
+	return w
 }
 
-func newCore(enabler zapcore.LevelEnabler) zapcore.Core {
+func newEncoder(cfg Config) zapcore.Encoder {
 	var encoder zapcore.Encoder
-	encoder = zapcore.NewConsoleEncoder(newEncoderConfig())
-	writer := zapcore.AddSync(&lumberjack.Logger{
-		Filename:   fmt.Sprintf("./log/%s.log", enabler),
-		MaxSize:    100,  // 文件大小，单位：M
-		MaxBackups: 50,   // 备份数量
-		MaxAge:     365,  // 日志保留天数
-		Compress:   true, // 是否压缩
-	})
-	return zapcore.NewCore(encoder, writer, enabler)
+	if cfg.Encoder == EncoderJSON {
+		encoder = zapcore.NewJSONEncoder(newEncoderConfig())
+	} else {
+		encoder = zapcore.NewConsoleEncoder(newEncoderConfig())
+	}
+	if len(cfg.MaskRules) > 0 {
+		encoder = newMaskEncoder(encoder, cfg.MaskRules)
+	}
+	return encoder
 }
 
 func newEncoderConfig() zapcore.EncoderConfig {
@@ -1,18 +1,183 @@
 package log
 
 import (
+	"context"
+	"encoding/json"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
+// TestMain 将默认的全局logger替换为输出到临时目录，测试不在./log下产生文件
+func TestMain(m *testing.M) {
+	dir, err := ioutil.TempDir("", "log")
+	if err != nil {
+		panic(err)
+	}
+	cfg := EnvDevelop.Config()
+	cfg.Dir = dir
+	ReplaceGlobal(New(cfg))
+	code := m.Run()
+	os.RemoveAll(dir)
+	os.Exit(code)
+}
+
+// tempDir 创建临时目录，由调用方删除
+func tempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return dir
+}
+
 func TestLog(t *testing.T) {
 	Info("hello")
 }
 
 func TestReplace(t *testing.T) {
-	if err := ReplaceGlobal(New(EnvProduct)); err != nil {
+	old := current()
+	defer ReplaceGlobal(old)
+
+	cfg := EnvProduct.Config()
+	cfg.Dir = tempDir(t)
+	defer os.RemoveAll(cfg.Dir)
+	if err := ReplaceGlobal(New(cfg)); err != nil {
+		t.Fatal(err)
+	}
+	if err := ReplaceGlobal(New(cfg)); err != nil {
 		t.Fatal(err)
 	}
-	if err := ReplaceGlobal(New(EnvProduct)); err != nil {
+	Info("replaced")
+	if _, err := os.Stat(filepath.Join(cfg.Dir, "info.log")); err != nil {
 		t.Fatal(err)
 	}
 }
+
+func TestNewConfig(t *testing.T) {
+	dir, err := ioutil.TempDir("", "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	cfg := EnvProduct.Config()
+	cfg.Dir = dir
+	cfg.FileName = "app-%s.log"
+	cfg.Encoder = EncoderJSON
+	logger := New(cfg)
+	logger.Debug("hidden")
+	logger.Info("hello", zap.String("k", "v"))
+	logger.Warn("careful")
+	if err := logger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	b, err := ioutil.ReadFile(filepath.Join(dir, "app-info.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(b)), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("info log has %d lines, want 2: %s", len(lines), b)
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal([]byte(lines[0]), &entry); err != nil {
+		t.Fatal(err)
+	}
+	if entry["M"] != "hello" || entry["k"] != "v" {
+		t.Fatalf("unexpected entry %v", entry)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "app-debug.log")); !os.IsNotExist(err) {
+		t.Fatalf("debug log written in product config: %v", err)
+	}
+	if b, err := ioutil.ReadFile(filepath.Join(dir, "app-warn.log")); err != nil || !strings.Contains(string(b), "careful") {
+		t.Fatalf("warn log missing entry: %v", err)
+	}
+}
+
+func TestNamed(t *testing.T) {
+	// 包级变量在替换全局logger前创建
+	mongo := Named("mongo")
+	cursor := mongo.Named("cursor").With(zap.String("db", "test"))
+
+	logs, restore := observe()
+	defer restore()
+	mongo.Info("first")
+	cursor.Info("second")
+	L().Info("third")
+
+	entries := logs.All()
+	if len(entries) != 3 {
+		t.Fatalf("got %d entries, want 3", len(entries))
+	}
+	if entries[0].LoggerName != "mongo" || entries[1].LoggerName != "mongo.cursor" || entries[2].LoggerName != "" {
+		t.Fatalf("logger names %q %q %q", entries[0].LoggerName, entries[1].LoggerName, entries[2].LoggerName)
+	}
+	if entries[1].ContextMap()["db"] != "test" {
+		t.Fatalf("fields %v", entries[1].ContextMap())
+	}
+	for _, e := range entries {
+		if !strings.HasSuffix(e.Caller.File, "log_test.go") {
+			t.Errorf("%s: caller %s, want log_test.go", e.Message, e.Caller.File)
+		}
+	}
+
+	// 再次替换后子logger跟随新的全局logger
+	logs2, restore2 := observe()
+	defer restore2()
+	mongo.Info("fourth")
+	if logs.Len() != 3 || logs2.Len() != 1 {
+		t.Fatalf("entries after second replace: %d, %d", logs.Len(), logs2.Len())
+	}
+	if err := ReplaceGlobal(nil); err == nil {
+		t.Fatal("nil logger replaced the global logger")
+	}
+}
+
+// TestCaller 全局logger按直接调用设置caller，各种调用方式均报告调用方所在文件
+func TestCaller(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	old := current()
+	defer ReplaceGlobal(old)
+	if err := ReplaceGlobal(zap.New(core, zap.AddCaller())); err != nil {
+		t.Fatal(err)
+	}
+	L().Info("L")
+	Info("Info")
+	Named("mongo").Info("Named")
+	Ctx(context.Background()).Info("Ctx")
+	for _, e := range logs.All() {
+		if filepath.Base(e.Caller.File) != "log_test.go" {
+			t.Errorf("%s: caller %s, want log_test.go", e.Message, e.Caller)
+		}
+	}
+	if logs.Len() != 4 {
+		t.Fatalf("%d entries, want 4", logs.Len())
+	}
+
+	// New返回的logger可直接使用
+	cfg := EnvDevelop.Config()
+	cfg.Dir = tempDir(t)
+	defer os.RemoveAll(cfg.Dir)
+	cfg.Encoder = EncoderJSON
+	logger := New(cfg)
+	logger.Info("direct")
+	logger.Sync()
+	b, err := ioutil.ReadFile(filepath.Join(cfg.Dir, "debug.log"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var entry map[string]interface{}
+	if err := json.Unmarshal(b, &entry); err != nil {
+		t.Fatal(err)
+	}
+	if caller, _ := entry["C"].(string); !strings.HasPrefix(caller, "log/log_test.go") {
+		t.Fatalf("direct caller %v, want log_test.go", entry["C"])
+	}
+}
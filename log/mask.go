@@ -0,0 +1,520 @@
+package log
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MaskFunc 返回脱敏后的值
+type MaskFunc func(string) string
+
+// MaskRule 脱敏规则，Key按字段名匹配（不区分大小写），Pattern按正则匹配字符串值及日志消息
+type MaskRule struct {
+	Key     string
+	Pattern *regexp.Regexp
+	Mask    MaskFunc
+}
+
+// DefaultMaskRules 默认脱敏规则：密码、token等字段，以及身份证号、手机号、邮箱
+var DefaultMaskRules = []MaskRule{
+	{Key: "password", Mask: MaskAll},
+	{Key: "passwd", Mask: MaskAll},
+	{Key: "token", Mask: MaskAll},
+	{Key: "secret", Mask: MaskAll},
+	{Key: "authorization", Mask: MaskAll},
+	{Pattern: regexp.MustCompile(`\b\d{17}[\dXx]\b`), Mask: MaskMiddle(3, 4)},
+	{Pattern: regexp.MustCompile(`\b1[3-9]\d{9}\b`), Mask: MaskMiddle(3, 4)},
+	{Pattern: regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), Mask: MaskEmail},
+}
+
+// MaskAll 整体替换为******
+func MaskAll(string) string {
+	return "******"
+}
+
+// MaskMiddle 保留前keepStart个及后keepEnd个字符，中间替换为*，长度不足时全部替换
+func MaskMiddle(keepStart, keepEnd int) MaskFunc {
+	return func(s string) string {
+		r := []rune(s)
+		if len(r) <= keepStart+keepEnd {
+			return strings.Repeat("*", len(r))
+		}
+		return string(r[:keepStart]) + strings.Repeat("*", len(r)-keepStart-keepEnd) + string(r[len(r)-keepEnd:])
+	}
+}
+
+// MaskEmail 保留邮箱用户名的首字符及域名
+func MaskEmail(s string) string {
+	i := strings.LastIndexByte(s, '@')
+	if i <= 0 {
+		return MaskAll(s)
+	}
+	return s[:1] + "***" + s[i:]
+}
+
+// Masked 返回脱敏后的字段，保留前3位及后4位，不依赖脱敏规则
+func Masked(key string, v interface{}) zap.Field {
+	return zap.String(key, MaskMiddle(3, 4)(fmt.Sprint(v)))
+}
+
+type masker struct {
+	keys     map[string]MaskFunc
+	patterns []MaskRule
+}
+
+func newMasker(rules []MaskRule) *masker {
+	m := &masker{keys: make(map[string]MaskFunc)}
+	for _, rule := range rules {
+		mask := rule.Mask
+		if mask == nil {
+			mask = MaskAll
+		}
+		if rule.Key != "" {
+			m.keys[strings.ToLower(rule.Key)] = mask
+		}
+		if rule.Pattern != nil {
+			m.patterns = append(m.patterns, MaskRule{Pattern: rule.Pattern, Mask: mask})
+		}
+	}
+	return m
+}
+
+func (m *masker) keyMask(key string) MaskFunc {
+	return m.keys[strings.ToLower(key)]
+}
+
+func (m *masker) maskString(s string) string {
+	for _, rule := range m.patterns {
+		s = rule.Pattern.ReplaceAllStringFunc(s, rule.Mask)
+	}
+	return s
+}
+
+// maskValue 脱敏任意值，结构体等先转换为JSON对象再逐个字段处理，数字保留原文，不转换为float64
+func (m *masker) maskValue(v interface{}) interface{} {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return v
+	}
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var decoded interface{}
+	if err := dec.Decode(&decoded); err != nil {
+		return v
+	}
+	return m.walk(decoded)
+}
+
+func (m *masker) walk(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return m.maskString(v)
+	case json.Number:
+		// 同整数字段，匹配正则时输出脱敏后的字符串
+		if masked := m.maskString(string(v)); masked != string(v) {
+			return masked
+		}
+	case []interface{}:
+		for i := range v {
+			v[i] = m.walk(v[i])
+		}
+	case map[string]interface{}:
+		for k, val := range v {
+			if mask := m.keyMask(k); mask != nil {
+				v[k] = mask(fmt.Sprint(val))
+			} else {
+				v[k] = m.walk(val)
+			}
+		}
+	}
+	return v
+}
+
+func (m *masker) addString(enc zapcore.ObjectEncoder, key, val string) {
+	if mask := m.keyMask(key); mask != nil {
+		enc.AddString(key, mask(val))
+		return
+	}
+	enc.AddString(key, m.maskString(val))
+}
+
+// maskKey 字段名匹配规则时以字符串形式输出脱敏后的值，返回是否已输出
+func (m *masker) maskKey(enc zapcore.ObjectEncoder, key string, v interface{}) bool {
+	if mask := m.keyMask(key); mask != nil {
+		enc.AddString(key, mask(fmt.Sprint(v)))
+		return true
+	}
+	return false
+}
+
+// maskInt 整数按字段名规则脱敏，或其十进制形式匹配正则（如数字形式的手机号）时输出脱敏后的字符串
+func (m *masker) maskInt(enc zapcore.ObjectEncoder, key string, v string) bool {
+	if m.maskKey(enc, key, v) {
+		return true
+	}
+	if masked := m.maskString(v); masked != v {
+		enc.AddString(key, masked)
+		return true
+	}
+	return false
+}
+
+// maskObjectEncoder 按脱敏规则处理写入的字段，用于日志字段及zapcore.ObjectMarshaler中的字段
+type maskObjectEncoder struct {
+	zapcore.ObjectEncoder
+	m *masker
+}
+
+func (e maskObjectEncoder) AddString(key, val string) { e.m.addString(e.ObjectEncoder, key, val) }
+
+func (e maskObjectEncoder) AddByteString(key string, val []byte) {
+	e.m.addString(e.ObjectEncoder, key, string(val))
+}
+
+func (e maskObjectEncoder) AddBinary(key string, val []byte) {
+	if !e.m.maskKey(e.ObjectEncoder, key, base64.StdEncoding.EncodeToString(val)) {
+		e.ObjectEncoder.AddBinary(key, val)
+	}
+}
+
+func (e maskObjectEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	if mask := e.m.keyMask(key); mask != nil {
+		e.ObjectEncoder.AddString(key, mask(""))
+		return nil
+	}
+	return e.ObjectEncoder.AddObject(key, maskedObject{obj: obj, m: e.m})
+}
+
+// AddArray 字段名匹配规则时逐个元素脱敏
+func (e maskObjectEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	return e.ObjectEncoder.AddArray(key, maskedArray{arr: arr, m: e.m, mask: e.m.keyMask(key)})
+}
+
+func (e maskObjectEncoder) AddReflected(key string, v interface{}) error {
+	if e.m.maskKey(e.ObjectEncoder, key, v) {
+		return nil
+	}
+	return e.ObjectEncoder.AddReflected(key, e.m.maskValue(v))
+}
+
+func (e maskObjectEncoder) AddBool(key string, v bool) {
+	if !e.m.maskKey(e.ObjectEncoder, key, v) {
+		e.ObjectEncoder.AddBool(key, v)
+	}
+}
+
+func (e maskObjectEncoder) AddComplex128(key string, v complex128) {
+	if !e.m.maskKey(e.ObjectEncoder, key, v) {
+		e.ObjectEncoder.AddComplex128(key, v)
+	}
+}
+
+func (e maskObjectEncoder) AddComplex64(key string, v complex64) {
+	if !e.m.maskKey(e.ObjectEncoder, key, v) {
+		e.ObjectEncoder.AddComplex64(key, v)
+	}
+}
+
+func (e maskObjectEncoder) AddFloat64(key string, v float64) {
+	if !e.m.maskKey(e.ObjectEncoder, key, v) {
+		e.ObjectEncoder.AddFloat64(key, v)
+	}
+}
+
+func (e maskObjectEncoder) AddFloat32(key string, v float32) {
+	if !e.m.maskKey(e.ObjectEncoder, key, v) {
+		e.ObjectEncoder.AddFloat32(key, v)
+	}
+}
+
+func (e maskObjectEncoder) AddDuration(key string, v time.Duration) {
+	if !e.m.maskKey(e.ObjectEncoder, key, v) {
+		e.ObjectEncoder.AddDuration(key, v)
+	}
+}
+
+func (e maskObjectEncoder) AddTime(key string, v time.Time) {
+	if !e.m.maskKey(e.ObjectEncoder, key, v) {
+		e.ObjectEncoder.AddTime(key, v)
+	}
+}
+
+func (e maskObjectEncoder) AddUintptr(key string, v uintptr) {
+	if !e.m.maskKey(e.ObjectEncoder, key, v) {
+		e.ObjectEncoder.AddUintptr(key, v)
+	}
+}
+
+func (e maskObjectEncoder) AddInt(key string, v int) {
+	if !e.m.maskInt(e.ObjectEncoder, key, strconv.FormatInt(int64(v), 10)) {
+		e.ObjectEncoder.AddInt(key, v)
+	}
+}
+
+func (e maskObjectEncoder) AddInt64(key string, v int64) {
+	if !e.m.maskInt(e.ObjectEncoder, key, strconv.FormatInt(v, 10)) {
+		e.ObjectEncoder.AddInt64(key, v)
+	}
+}
+
+func (e maskObjectEncoder) AddInt32(key string, v int32) {
+	if !e.m.maskInt(e.ObjectEncoder, key, strconv.FormatInt(int64(v), 10)) {
+		e.ObjectEncoder.AddInt32(key, v)
+	}
+}
+
+func (e maskObjectEncoder) AddInt16(key string, v int16) {
+	if !e.m.maskInt(e.ObjectEncoder, key, strconv.FormatInt(int64(v), 10)) {
+		e.ObjectEncoder.AddInt16(key, v)
+	}
+}
+
+func (e maskObjectEncoder) AddInt8(key string, v int8) {
+	if !e.m.maskInt(e.ObjectEncoder, key, strconv.FormatInt(int64(v), 10)) {
+		e.ObjectEncoder.AddInt8(key, v)
+	}
+}
+
+func (e maskObjectEncoder) AddUint(key string, v uint) {
+	if !e.m.maskInt(e.ObjectEncoder, key, strconv.FormatUint(uint64(v), 10)) {
+		e.ObjectEncoder.AddUint(key, v)
+	}
+}
+
+func (e maskObjectEncoder) AddUint64(key string, v uint64) {
+	if !e.m.maskInt(e.ObjectEncoder, key, strconv.FormatUint(v, 10)) {
+		e.ObjectEncoder.AddUint64(key, v)
+	}
+}
+
+func (e maskObjectEncoder) AddUint32(key string, v uint32) {
+	if !e.m.maskInt(e.ObjectEncoder, key, strconv.FormatUint(uint64(v), 10)) {
+		e.ObjectEncoder.AddUint32(key, v)
+	}
+}
+
+func (e maskObjectEncoder) AddUint16(key string, v uint16) {
+	if !e.m.maskInt(e.ObjectEncoder, key, strconv.FormatUint(uint64(v), 10)) {
+		e.ObjectEncoder.AddUint16(key, v)
+	}
+}
+
+func (e maskObjectEncoder) AddUint8(key string, v uint8) {
+	if !e.m.maskInt(e.ObjectEncoder, key, strconv.FormatUint(uint64(v), 10)) {
+		e.ObjectEncoder.AddUint8(key, v)
+	}
+}
+
+// maskEncoder 在编码时按脱敏规则处理日志消息及字段
+type maskEncoder struct {
+	maskObjectEncoder
+	enc zapcore.Encoder
+}
+
+func newMaskEncoder(enc zapcore.Encoder, rules []MaskRule) zapcore.Encoder {
+	return maskEncoder{maskObjectEncoder: maskObjectEncoder{ObjectEncoder: enc, m: newMasker(rules)}, enc: enc}
+}
+
+func (e maskEncoder) Clone() zapcore.Encoder {
+	enc := e.enc.Clone()
+	return maskEncoder{maskObjectEncoder: maskObjectEncoder{ObjectEncoder: enc, m: e.m}, enc: enc}
+}
+
+func (e maskEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	ent.Message = e.m.maskString(ent.Message)
+	// 字段先经过脱敏后加入副本，再由原编码器输出
+	clone := e.Clone().(maskEncoder)
+	for i := range fields {
+		fields[i].AddTo(clone)
+	}
+	return clone.enc.EncodeEntry(ent, nil)
+}
+
+type maskedObject struct {
+	obj zapcore.ObjectMarshaler
+	m   *masker
+}
+
+func (o maskedObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	return o.obj.MarshalLogObject(maskObjectEncoder{ObjectEncoder: enc, m: o.m})
+}
+
+// maskedArray 对数组元素脱敏，mask非nil时（字段名匹配规则）所有元素均用其脱敏，否则按正则及字段名规则处理
+type maskedArray struct {
+	arr  zapcore.ArrayMarshaler
+	m    *masker
+	mask MaskFunc
+}
+
+func (a maskedArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	return a.arr.MarshalLogArray(maskArrayEncoder{ArrayEncoder: enc, m: a.m, mask: a.mask})
+}
+
+type maskArrayEncoder struct {
+	zapcore.ArrayEncoder
+	m    *masker
+	mask MaskFunc
+}
+
+// maskAll mask非nil时以字符串形式追加脱敏后的元素，返回是否已追加
+func (e maskArrayEncoder) maskAll(v interface{}) bool {
+	if e.mask != nil {
+		e.ArrayEncoder.AppendString(e.mask(fmt.Sprint(v)))
+		return true
+	}
+	return false
+}
+
+func (e maskArrayEncoder) maskInt(v string) bool {
+	if e.maskAll(v) {
+		return true
+	}
+	if masked := e.m.maskString(v); masked != v {
+		e.ArrayEncoder.AppendString(masked)
+		return true
+	}
+	return false
+}
+
+func (e maskArrayEncoder) AppendString(v string) {
+	if !e.maskAll(v) {
+		e.ArrayEncoder.AppendString(e.m.maskString(v))
+	}
+}
+
+func (e maskArrayEncoder) AppendByteString(v []byte) { e.AppendString(string(v)) }
+
+func (e maskArrayEncoder) AppendObject(obj zapcore.ObjectMarshaler) error {
+	if e.maskAll("") {
+		return nil
+	}
+	return e.ArrayEncoder.AppendObject(maskedObject{obj: obj, m: e.m})
+}
+
+func (e maskArrayEncoder) AppendArray(arr zapcore.ArrayMarshaler) error {
+	return e.ArrayEncoder.AppendArray(maskedArray{arr: arr, m: e.m, mask: e.mask})
+}
+
+func (e maskArrayEncoder) AppendReflected(v interface{}) error {
+	if e.maskAll(v) {
+		return nil
+	}
+	return e.ArrayEncoder.AppendReflected(e.m.maskValue(v))
+}
+
+func (e maskArrayEncoder) AppendBool(v bool) {
+	if !e.maskAll(v) {
+		e.ArrayEncoder.AppendBool(v)
+	}
+}
+
+func (e maskArrayEncoder) AppendComplex128(v complex128) {
+	if !e.maskAll(v) {
+		e.ArrayEncoder.AppendComplex128(v)
+	}
+}
+
+func (e maskArrayEncoder) AppendComplex64(v complex64) {
+	if !e.maskAll(v) {
+		e.ArrayEncoder.AppendComplex64(v)
+	}
+}
+
+func (e maskArrayEncoder) AppendFloat64(v float64) {
+	if !e.maskAll(v) {
+		e.ArrayEncoder.AppendFloat64(v)
+	}
+}
+
+func (e maskArrayEncoder) AppendFloat32(v float32) {
+	if !e.maskAll(v) {
+		e.ArrayEncoder.AppendFloat32(v)
+	}
+}
+
+func (e maskArrayEncoder) AppendDuration(v time.Duration) {
+	if !e.maskAll(v) {
+		e.ArrayEncoder.AppendDuration(v)
+	}
+}
+
+func (e maskArrayEncoder) AppendTime(v time.Time) {
+	if !e.maskAll(v) {
+		e.ArrayEncoder.AppendTime(v)
+	}
+}
+
+func (e maskArrayEncoder) AppendUintptr(v uintptr) {
+	if !e.maskAll(v) {
+		e.ArrayEncoder.AppendUintptr(v)
+	}
+}
+
+func (e maskArrayEncoder) AppendInt(v int) {
+	if !e.maskInt(strconv.FormatInt(int64(v), 10)) {
+		e.ArrayEncoder.AppendInt(v)
+	}
+}
+
+func (e maskArrayEncoder) AppendInt64(v int64) {
+	if !e.maskInt(strconv.FormatInt(v, 10)) {
+		e.ArrayEncoder.AppendInt64(v)
+	}
+}
+
+func (e maskArrayEncoder) AppendInt32(v int32) {
+	if !e.maskInt(strconv.FormatInt(int64(v), 10)) {
+		e.ArrayEncoder.AppendInt32(v)
+	}
+}
+
+func (e maskArrayEncoder) AppendInt16(v int16) {
+	if !e.maskInt(strconv.FormatInt(int64(v), 10)) {
+		e.ArrayEncoder.AppendInt16(v)
+	}
+}
+
+func (e maskArrayEncoder) AppendInt8(v int8) {
+	if !e.maskInt(strconv.FormatInt(int64(v), 10)) {
+		e.ArrayEncoder.AppendInt8(v)
+	}
+}
+
+func (e maskArrayEncoder) AppendUint(v uint) {
+	if !e.maskInt(strconv.FormatUint(uint64(v), 10)) {
+		e.ArrayEncoder.AppendUint(v)
+	}
+}
+
+func (e maskArrayEncoder) AppendUint64(v uint64) {
+	if !e.maskInt(strconv.FormatUint(v, 10)) {
+		e.ArrayEncoder.AppendUint64(v)
+	}
+}
+
+func (e maskArrayEncoder) AppendUint32(v uint32) {
+	if !e.maskInt(strconv.FormatUint(uint64(v), 10)) {
+		e.ArrayEncoder.AppendUint32(v)
+	}
+}
+
+func (e maskArrayEncoder) AppendUint16(v uint16) {
+	if !e.maskInt(strconv.FormatUint(uint64(v), 10)) {
+		e.ArrayEncoder.AppendUint16(v)
+	}
+}
+
+func (e maskArrayEncoder) AppendUint8(v uint8) {
+	if !e.maskInt(strconv.FormatUint(uint64(v), 10)) {
+		e.ArrayEncoder.AppendUint8(v)
+	}
+}
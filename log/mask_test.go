@@ -0,0 +1,173 @@
+package log
+
+import (
+	"bytes"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+type maskUser struct {
+	Name     string `json:"name"`
+	Phone    string `json:"phone"`
+	Password string `json:"password"`
+}
+
+// plainUser 未实现zapcore.ObjectMarshaler，通过反射输出
+type plainUser maskUser
+
+func (u maskUser) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", u.Name)
+	enc.AddString("phone", u.Phone)
+	enc.AddString("password", u.Password)
+	return nil
+}
+
+func TestMask(t *testing.T) {
+	var buf bytes.Buffer
+	rules := append([]MaskRule{
+		{Key: "card", Mask: MaskMiddle(0, 4)},
+		{Pattern: regexp.MustCompile(`sk_[0-9a-z]+`)},
+	}, DefaultMaskRules...)
+	encoder := newMaskEncoder(zapcore.NewJSONEncoder(newEncoderConfig()), rules)
+	logger := zap.New(zapcore.NewCore(encoder, zapcore.AddSync(&buf), zapcore.DebugLevel))
+	user := maskUser{Name: "alice", Phone: "13812345678", Password: "hunter2"}
+
+	logger.With(zap.String("Token", "abc123")).Info("login 13812345678 alice@example.com",
+		zap.String("password", "hunter2"),
+		zap.String("id", "110101199003071234"),
+		zap.Int("card", 6222020200112233),
+		zap.String("key", "sk_live42"),
+		zap.Any("user", plainUser(user)),
+		zap.Object("obj", user),
+		Masked("mobile", "13912345678"),
+	)
+
+	out := buf.String()
+	for _, secret := range []string{"hunter2", "abc123", "13812345678", "alice@example.com", "110101199003071234",
+		"6222020200112233", "sk_live42", "13912345678"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("%s not masked: %s", secret, out)
+		}
+	}
+	for _, masked := range []string{
+		`"M":"login 138****5678 a***@example.com"`,
+		`"Token":"******"`,
+		`"password":"******"`,
+		`"id":"110***********1234"`,
+		`"card":"************2233"`,
+		`"key":"******"`,
+		`"user":{"name":"alice","password":"******","phone":"138****5678"}`,
+		`"obj":{"name":"alice","phone":"138****5678","password":"******"}`,
+		`"mobile":"139****5678"`,
+	} {
+		if !strings.Contains(out, masked) {
+			t.Errorf("output does not contain %s: %s", masked, out)
+		}
+	}
+}
+
+type maskUsers []maskUser
+
+func (us maskUsers) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, u := range us {
+		enc.AppendObject(u)
+	}
+	return nil
+}
+
+func TestMaskFieldTypes(t *testing.T) {
+	var buf bytes.Buffer
+	encoder := newMaskEncoder(zapcore.NewJSONEncoder(newEncoderConfig()), DefaultMaskRules)
+	logger := zap.New(zapcore.NewCore(encoder, zapcore.AddSync(&buf), zapcore.DebugLevel))
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+
+	// 字段名匹配规则时，各类型的值均以脱敏后的字符串输出
+	for _, f := range []zap.Field{
+		zap.Binary("secret", []byte("hunter2")),
+		zap.ByteString("secret", []byte("hunter2")),
+		zap.Bool("secret", true),
+		zap.Complex128("secret", 1+2i),
+		zap.Complex64("secret", 1+2i),
+		zap.Duration("secret", time.Second),
+		zap.Float64("secret", 1.5),
+		zap.Float32("secret", 1.5),
+		zap.Int("secret", 42),
+		zap.Int64("secret", 42),
+		zap.Int32("secret", 42),
+		zap.Int16("secret", 42),
+		zap.Int8("secret", 42),
+		zap.Uint("secret", 42),
+		zap.Uint64("secret", 42),
+		zap.Uint32("secret", 42),
+		zap.Uint16("secret", 42),
+		zap.Uint8("secret", 42),
+		zap.Uintptr("secret", 42),
+		zap.Time("secret", now),
+		zap.Strings("secret", []string{"a", "b"}),
+		zap.Int32s("secret", []int32{1, 2}),
+		zap.Bools("secret", []bool{true}),
+		zap.Durations("secret", []time.Duration{time.Second}),
+		zap.Times("secret", []time.Time{now}),
+		zap.Array("secret", maskUsers{{Name: "alice"}}),
+	} {
+		buf.Reset()
+		logger.Info("", f)
+		if !strings.Contains(buf.String(), `"secret":"******"`) && !strings.Contains(buf.String(), `"secret":["******"`) {
+			t.Errorf("%v field not masked: %s", f.Type, buf.String())
+		}
+	}
+
+	buf.Reset()
+	logger.Info("",
+		zap.Strings("token", []string{"abc", "def"}),
+		zap.Int32("password", 123456),
+		zap.Uint64("phone", 13812345678),
+		zap.Int64s("mobiles", []int64{13912345678}),
+		zap.Strings("contacts", []string{"13712345678", "alice"}),
+		zap.Array("users", maskUsers{{Name: "bob", Phone: "13612345678", Password: "hunter2"}}),
+		zap.Int("count", 3),
+	)
+	out := buf.String()
+	for _, secret := range []string{"abc", "def", "123456", "13812345678", "13912345678", "13712345678", "13612345678", "hunter2"} {
+		if strings.Contains(out, secret) {
+			t.Errorf("%s not masked: %s", secret, out)
+		}
+	}
+	for _, masked := range []string{
+		`"token":["******","******"]`,
+		`"password":"******"`,
+		`"phone":"138****5678"`,
+		`"mobiles":["139****5678"]`,
+		`"contacts":["137****5678","alice"]`,
+		`"users":[{"name":"bob","phone":"136****5678","password":"******"}]`,
+		`"count":3`,
+	} {
+		if !strings.Contains(out, masked) {
+			t.Errorf("output does not contain %s: %s", masked, out)
+		}
+	}
+}
+
+func TestMaskReflectedNumbers(t *testing.T) {
+	var buf bytes.Buffer
+	rules := append([]MaskRule{{Key: "phone", Mask: MaskMiddle(3, 4)}}, DefaultMaskRules...)
+	encoder := newMaskEncoder(zapcore.NewJSONEncoder(newEncoderConfig()), rules)
+	logger := zap.New(zapcore.NewCore(encoder, zapcore.AddSync(&buf), zapcore.DebugLevel))
+	type account struct {
+		ID      int64   `json:"id"`
+		Phone   int64   `json:"phone"`
+		Mobile  int64   `json:"mobile"`
+		Balance float64 `json:"balance"`
+	}
+
+	logger.Info("", zap.Any("account", account{ID: 1234567890123456789, Phone: 13812345678, Mobile: 13912345678, Balance: 0.1}))
+	out := buf.String()
+	want := `"account":{"balance":0.1,"id":1234567890123456789,"mobile":"139****5678","phone":"138****5678"}`
+	if !strings.Contains(out, want) {
+		t.Fatalf("output does not contain %s: %s", want, out)
+	}
+}
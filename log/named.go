@@ -0,0 +1,91 @@
+package log
+
+import (
+	"go.uber.org/zap"
+	"sync/atomic"
+)
+
+// Logger 跟随全局logger替换的子logger，可安全地保存在包级变量中
+type Logger struct {
+	name   string
+	fields []zap.Field
+	cache  atomic.Value // *derived
+}
+
+// derived 由某个全局logger派生出的logger
+type derived struct {
+	base   *zap.Logger
+	logger *zap.Logger // 供Logger方法及包级函数使用，跳过其自身的栈帧
+	direct *zap.Logger // 供调用方直接使用，即全局logger本身
+}
+
+var root = &Logger{}
+
+// L 返回当前的全局logger，ReplaceGlobal后需重新获取，包级变量请使用Named
+func L() *zap.Logger {
+	return root.get().direct
+}
+
+// Named 返回名称为name的子logger，多级名称以.分隔，可通过SetModuleLevel单独设置级别
+func Named(name string) *Logger {
+	return root.Named(name)
+}
+
+// Named 返回名称为l的名称加.name的子logger
+func (l *Logger) Named(name string) *Logger {
+	if l.name != "" {
+		name = l.name + "." + name
+	}
+	return &Logger{name: name, fields: l.fields}
+}
+
+// With 返回带有字段的子logger
+func (l *Logger) With(fields ...zap.Field) *Logger {
+	merged := make([]zap.Field, 0, len(l.fields)+len(fields))
+	merged = append(merged, l.fields...)
+	merged = append(merged, fields...)
+	return &Logger{name: l.name, fields: merged}
+}
+
+// L 返回当前全局logger派生出的*zap.Logger
+func (l *Logger) L() *zap.Logger {
+	return l.get().direct
+}
+
+func (l *Logger) Debug(msg string, field ...zap.Field) {
+	l.get().logger.Debug(msg, field...)
+}
+
+func (l *Logger) Info(msg string, field ...zap.Field) {
+	l.get().logger.Info(msg, field...)
+}
+
+func (l *Logger) Warn(msg string, field ...zap.Field) {
+	l.get().logger.Warn(msg, field...)
+}
+
+func (l *Logger) Error(msg string, field ...zap.Field) {
+	l.get().logger.Error(msg, field...)
+}
+
+// get 返回由当前全局logger派生的logger，全局logger未替换时使用缓存
+func (l *Logger) get() *derived {
+	base := current()
+	if d, ok := l.cache.Load().(*derived); ok && d.base == base {
+		return d
+	}
+	logger := base
+	if l.name != "" {
+		logger = logger.Named(l.name)
+	}
+	if len(l.fields) > 0 {
+		logger = logger.With(l.fields...)
+	}
+	d := &derived{
+		base:   base,
+		logger: logger.WithOptions(zap.AddCallerSkip(1)),
+		direct: logger,
+	}
+	l.cache.Store(d)
+	return d
+}
@@ -0,0 +1,275 @@
+package log
+
+import (
+	"compress/gzip"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RotatePeriod 按时间切分的周期
+type RotatePeriod int
+
+const (
+	RotateDaily  RotatePeriod = iota // 每天零点切分，文件名如 info-2026-10-18.log
+	RotateHourly                     // 每小时切分，文件名如 info-2026-10-18-15.log
+)
+
+func (p RotatePeriod) layout() string {
+	if p == RotateHourly {
+		return "2006-01-02-15"
+	}
+	return "2006-01-02"
+}
+
+// RotationConfig 按时间切分配置，通过Config.Rotation使用时，MaxAge为0则使用Config.MaxAge，
+// Compress与Config.Compress任一为true即压缩
+type RotationConfig struct {
+	Period       RotatePeriod
+	Location     *time.Location // 切分所用时区，默认time.Local
+	MaxAge       int            // 日志保留天数，为0时不按时间清理
+	MaxTotalSize int            // 所有文件的总大小，单位：M，超出时删除最旧的文件，为0时不限制
+	Compress     bool           // 是否gzip压缩已切分的文件
+}
+
+var errRotateClosed = errors.New("rotate writer is closed")
+
+const compressSuffix = ".gz"
+
+// TimeRotateWriter 按时间切分的writer，文件名在扩展名前加上周期开始时间
+type TimeRotateWriter struct {
+	filename string
+	cfg      RotationConfig
+	now      func() time.Time
+
+	mu     sync.Mutex
+	file   *os.File
+	name   string // 当前文件名，关闭后仍保留，避免清理时被压缩或删除
+	next   time.Time
+	closed bool
+
+	millCh  chan struct{}
+	stopped chan struct{}
+}
+
+// NewTimeRotateWriter 创建按时间切分的writer，filename如 ./log/info.log，不再使用时需调用Close
+func NewTimeRotateWriter(filename string, cfg RotationConfig) *TimeRotateWriter {
+	if cfg.Location == nil {
+		cfg.Location = time.Local
+	}
+	w := &TimeRotateWriter{
+		filename: filename,
+		cfg:      cfg,
+		now:      time.Now,
+		millCh:   make(chan struct{}, 1),
+		stopped:  make(chan struct{}),
+	}
+	go w.runMill()
+	return w
+}
+
+func (w *TimeRotateWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.closed {
+		return 0, errRotateClosed
+	}
+	now := w.now().In(w.cfg.Location)
+	if w.file == nil || !now.Before(w.next) {
+		if err := w.rotate(now); err != nil {
+			return 0, err
+		}
+	}
+	return w.file.Write(p)
+}
+
+// Sync 将文件内容同步到磁盘
+func (w *TimeRotateWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Close 关闭当前文件，并等待清理及压缩完成
+func (w *TimeRotateWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		return nil
+	}
+	w.closed = true
+	var err error
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	w.mu.Unlock()
+	close(w.millCh)
+	<-w.stopped
+	return err
+}
+
+// rotate 关闭当前文件并打开now所在周期的文件
+func (w *TimeRotateWriter) rotate(now time.Time) error {
+	start := w.periodStart(now)
+	name := w.nameFor(start)
+	if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+		return err
+	}
+	f, err := os.OpenFile(name, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return err
+	}
+	if w.file != nil {
+		w.file.Close()
+	}
+	w.file = f
+	w.name = name
+	if w.cfg.Period == RotateHourly {
+		w.next = start.Add(time.Hour)
+	} else {
+		w.next = start.AddDate(0, 0, 1)
+	}
+	select {
+	case w.millCh <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (w *TimeRotateWriter) periodStart(t time.Time) time.Time {
+	if w.cfg.Period == RotateHourly {
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func (w *TimeRotateWriter) nameFor(t time.Time) string {
+	ext := filepath.Ext(w.filename)
+	return strings.TrimSuffix(w.filename, ext) + "-" + t.Format(w.cfg.Period.layout()) + ext
+}
+
+func (w *TimeRotateWriter) runMill() {
+	defer close(w.stopped)
+	for range w.millCh {
+		w.mill()
+	}
+}
+
+type rotatedFile struct {
+	path string
+	t    time.Time
+	size int64
+}
+
+// mill 压缩已切分的文件，并按保留天数及总大小删除旧文件，当前文件不受影响
+func (w *TimeRotateWriter) mill() {
+	files := w.rotatedFiles()
+	if w.cfg.Compress {
+		for i, f := range files {
+			if w.active(f) || strings.HasSuffix(f.path, compressSuffix) {
+				continue
+			}
+			if err := compressFile(f.path); err != nil {
+				continue
+			}
+			files[i].path += compressSuffix
+			if fi, err := os.Stat(files[i].path); err == nil {
+				files[i].size = fi.Size()
+			}
+		}
+	}
+
+	// 从新到旧累计大小
+	sort.Slice(files, func(i, j int) bool { return files[i].t.After(files[j].t) })
+	var cutoff time.Time
+	if w.cfg.MaxAge > 0 {
+		cutoff = w.now().AddDate(0, 0, -w.cfg.MaxAge)
+	}
+	var total int64
+	for _, f := range files {
+		total += f.size
+		if w.active(f) {
+			continue
+		}
+		expired := !cutoff.IsZero() && f.t.Before(w.periodStart(cutoff.In(w.cfg.Location)))
+		oversize := w.cfg.MaxTotalSize > 0 && total > int64(w.cfg.MaxTotalSize)*1024*1024
+		if expired || oversize {
+			os.Remove(f.path)
+			total -= f.size
+		}
+	}
+}
+
+// active 判断f是否为正在写入或之后的周期的文件，清理较慢时期间可能已切分到新的文件，因此每次处理前重新判断
+func (w *TimeRotateWriter) active(f rotatedFile) bool {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return f.path == w.name || !f.t.Before(w.periodStart(w.now().In(w.cfg.Location)))
+}
+
+// rotatedFiles 返回目录中由该writer产生的文件
+func (w *TimeRotateWriter) rotatedFiles() []rotatedFile {
+	dir := filepath.Dir(w.filename)
+	ext := filepath.Ext(w.filename)
+	prefix := strings.TrimSuffix(filepath.Base(w.filename), ext) + "-"
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil
+	}
+	var files []rotatedFile
+	for _, fi := range infos {
+		name := fi.Name()
+		if fi.IsDir() || !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		ts := strings.TrimSuffix(strings.TrimSuffix(name, compressSuffix), ext)
+		t, err := time.ParseInLocation(w.cfg.Period.layout(), strings.TrimPrefix(ts, prefix), w.cfg.Location)
+		if err != nil {
+			continue
+		}
+		files = append(files, rotatedFile{path: filepath.Join(dir, name), t: t, size: fi.Size()})
+	}
+	return files
+}
+
+// compressFile gzip压缩文件并删除原文件
+func compressFile(name string) (err error) {
+	src, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+	dst, err := os.OpenFile(name+compressSuffix, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			os.Remove(name + compressSuffix)
+		}
+	}()
+	gz := gzip.NewWriter(dst)
+	if _, err = io.Copy(gz, src); err != nil {
+		dst.Close()
+		return err
+	}
+	if err = gz.Close(); err != nil {
+		dst.Close()
+		return err
+	}
+	if err = dst.Close(); err != nil {
+		return err
+	}
+	src.Close()
+	return os.Remove(name)
+}
@@ -0,0 +1,200 @@
+package log
+
+import (
+	"compress/gzip"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+)
+
+func listDir(t *testing.T, dir string) []string {
+	infos, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, fi := range infos {
+		names = append(names, fi.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func equalNames(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func TestTimeRotateDaily(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	cst := time.FixedZone("CST", 8*3600)
+	w := NewTimeRotateWriter(filepath.Join(dir, "info.log"), RotationConfig{Location: cst})
+	now := time.Date(2026, 10, 18, 15, 30, 0, 0, time.UTC) // 23:30 CST
+	w.now = func() time.Time { return now }
+	w.Write([]byte("a\n"))
+	now = now.Add(20 * time.Minute)
+	w.Write([]byte("b\n"))
+	now = now.Add(40 * time.Minute) // 次日00:30 CST，UTC仍为18日
+	w.Write([]byte("c\n"))
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []string{"info-2026-10-18.log", "info-2026-10-19.log"}
+	if got := listDir(t, dir); !equalNames(got, want) {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+	b, _ := ioutil.ReadFile(filepath.Join(dir, want[0]))
+	if string(b) != "a\nb\n" {
+		t.Fatalf("%s = %q", want[0], b)
+	}
+}
+
+func TestTimeRotateHourly(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	w := NewTimeRotateWriter(filepath.Join(dir, "error.log"), RotationConfig{Period: RotateHourly, Location: time.UTC})
+	now := time.Date(2026, 10, 18, 9, 59, 0, 0, time.UTC)
+	w.now = func() time.Time { return now }
+	w.Write([]byte("a\n"))
+	now = now.Add(time.Minute)
+	w.Write([]byte("b\n"))
+	w.Close()
+
+	want := []string{"error-2026-10-18-09.log", "error-2026-10-18-10.log"}
+	if got := listDir(t, dir); !equalNames(got, want) {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+}
+
+func TestTimeRotateRetention(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	for _, name := range []string{"info-2026-10-01.log", "info-2026-10-15.log", "warn-2026-10-01.log"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("old\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w := NewTimeRotateWriter(filepath.Join(dir, "info.log"), RotationConfig{Location: time.UTC, MaxAge: 7, Compress: true})
+	w.now = func() time.Time { return time.Date(2026, 10, 18, 12, 0, 0, 0, time.UTC) }
+	w.Write([]byte("new\n"))
+	w.Close()
+
+	// 过期文件被删除，未过期的被压缩，当前文件及其他级别的文件不受影响
+	want := []string{"info-2026-10-15.log.gz", "info-2026-10-18.log", "warn-2026-10-01.log"}
+	if got := listDir(t, dir); !equalNames(got, want) {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+	f, err := os.Open(filepath.Join(dir, want[0]))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	gz, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := ioutil.ReadAll(gz); string(b) != "old\n" {
+		t.Fatalf("decompressed %q", b)
+	}
+}
+
+func TestTimeRotateMillActive(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "info-2026-10-16.log"), []byte("old\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w := NewTimeRotateWriter(filepath.Join(dir, "info.log"), RotationConfig{Location: time.UTC, Compress: true})
+	now := time.Date(2026, 10, 17, 23, 59, 59, 0, time.UTC)
+	w.now = func() time.Time { return now }
+	w.Write([]byte("a\n"))
+	w.Close()
+
+	// 模拟清理期间已切分到新的一天：按周期判断，新的当前文件不会被压缩
+	now = now.Add(2 * time.Second)
+	if err := ioutil.WriteFile(filepath.Join(dir, "info-2026-10-18.log"), []byte("b\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	w.mill()
+
+	want := []string{"info-2026-10-16.log.gz", "info-2026-10-17.log", "info-2026-10-18.log"}
+	if got := listDir(t, dir); !equalNames(got, want) {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+}
+
+func TestTimeRotateMaxTotalSize(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	big := make([]byte, 600*1024)
+	for _, name := range []string{"info-2026-10-16.log", "info-2026-10-17.log"} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), big, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	w := NewTimeRotateWriter(filepath.Join(dir, "info.log"), RotationConfig{Location: time.UTC, MaxTotalSize: 1})
+	w.now = func() time.Time { return time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC) }
+	w.Write([]byte("new\n"))
+	w.Close()
+
+	want := []string{"info-2026-10-17.log", "info-2026-10-18.log"}
+	if got := listDir(t, dir); !equalNames(got, want) {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+}
+
+func TestNewRotation(t *testing.T) {
+	dir := tempDir(t)
+	defer os.RemoveAll(dir)
+
+	now := time.Now().UTC()
+	expired := "info-" + now.AddDate(-2, 0, 0).Format("2006-01-02") + ".log"
+	yesterday := "info-" + now.AddDate(0, 0, -1).Format("2006-01-02") + ".log"
+	for _, name := range []string{expired, yesterday} {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), []byte("old\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Rotation未设置MaxAge、Compress时使用Config中的365天及压缩
+	cfg := EnvProduct.Config()
+	cfg.Dir = dir
+	cfg.Rotation = &RotationConfig{Location: time.UTC}
+	logger := New(cfg)
+	logger.Info("rotated")
+	if err := Close(logger); err != nil {
+		t.Fatal(err)
+	}
+
+	name := "info-" + time.Now().UTC().Format("2006-01-02") + ".log"
+	b, err := ioutil.ReadFile(filepath.Join(dir, name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(b) == 0 {
+		t.Fatalf("%s is empty", name)
+	}
+	if _, err := os.Stat(filepath.Join(dir, expired)); !os.IsNotExist(err) {
+		t.Fatalf("%s not removed: %v", expired, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, yesterday+compressSuffix)); err != nil {
+		t.Fatalf("%s not compressed: %v", yesterday, err)
+	}
+}
@@ -0,0 +1,247 @@
+package log
+
+import (
+	"container/list"
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"sync"
+	"time"
+)
+
+// SamplingConfig 采样及限流配置
+type SamplingConfig struct {
+	First      int // 每秒每条消息（按级别及消息区分）前First条全部输出，为0时不采样
+	Thereafter int // 超过First条后每Thereafter条输出1条，为0时全部丢弃
+
+	RateLimitKey string  // 限流字段名，按消息及该字段的值分别限流，为空时仅按消息；With添加的字段同样生效
+	Rate         float64 // 限流速率，每秒条数，为0时不限流
+	Burst        int     // 限流突发条数，默认等于Rate
+	MaxBuckets   int     // 限流桶数量上限，超出时淘汰最久未使用的桶，默认10000
+
+	SummaryInterval time.Duration // 输出被抑制条数的间隔，默认1分钟
+}
+
+// SummaryMessage 被抑制日志汇总行的消息
+const SummaryMessage = "log entries suppressed"
+
+type sampleKey struct {
+	level   zapcore.Level
+	message string
+	value   string
+}
+
+type bucket struct {
+	key    sampleKey
+	tokens float64
+	last   time.Time
+}
+
+// sampler 采样及限流状态，由同一logger派生的core共用
+type sampler struct {
+	cfg  SamplingConfig
+	core zapcore.Core // 用于输出汇总行
+	now  func() time.Time
+
+	mu         sync.Mutex
+	window     time.Time
+	counts     map[sampleKey]int
+	buckets    map[sampleKey]*list.Element // 值为*bucket
+	lru        *list.List                  // 最近使用的桶在前
+	suppressed map[string]uint64
+
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func newSampler(core zapcore.Core, cfg SamplingConfig) *sampler {
+	if cfg.Burst <= 0 {
+		cfg.Burst = int(cfg.Rate)
+		if cfg.Burst < 1 {
+			cfg.Burst = 1
+		}
+	}
+	if cfg.MaxBuckets <= 0 {
+		cfg.MaxBuckets = 10000
+	}
+	if cfg.SummaryInterval <= 0 {
+		cfg.SummaryInterval = time.Minute
+	}
+	return &sampler{
+		cfg:        cfg,
+		core:       core,
+		now:        time.Now,
+		counts:     make(map[sampleKey]int),
+		buckets:    make(map[sampleKey]*list.Element),
+		lru:        list.New(),
+		suppressed: make(map[string]uint64),
+		done:       make(chan struct{}),
+		stopped:    make(chan struct{}),
+	}
+}
+
+// allow 判断日志是否输出，不输出时计入被抑制条数，value为限流字段的值
+func (s *sampler) allow(ent zapcore.Entry, value string) bool {
+	key := sampleKey{level: ent.Level, message: ent.Message}
+	now := s.now()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.cfg.First > 0 {
+		if now.Sub(s.window) >= time.Second {
+			s.window = now
+			s.counts = make(map[sampleKey]int)
+		}
+		s.counts[key]++
+		n := s.counts[key]
+		if n > s.cfg.First && (s.cfg.Thereafter <= 0 || (n-s.cfg.First)%s.cfg.Thereafter != 0) {
+			s.suppressed[ent.Message]++
+			return false
+		}
+	}
+	if s.cfg.Rate > 0 {
+		key.value = value
+		b := s.bucket(key, now)
+		b.tokens += now.Sub(b.last).Seconds() * s.cfg.Rate
+		if b.tokens > float64(s.cfg.Burst) {
+			b.tokens = float64(s.cfg.Burst)
+		}
+		b.last = now
+		if b.tokens < 1 {
+			s.suppressed[ent.Message]++
+			return false
+		}
+		b.tokens--
+	}
+	return true
+}
+
+// bucket 返回key的限流桶，桶数量达到上限时淘汰最久未使用的桶
+func (s *sampler) bucket(key sampleKey, now time.Time) *bucket {
+	if e, ok := s.buckets[key]; ok {
+		s.lru.MoveToFront(e)
+		return e.Value.(*bucket)
+	}
+	if s.lru.Len() >= s.cfg.MaxBuckets {
+		e := s.lru.Back()
+		delete(s.buckets, e.Value.(*bucket).key)
+		s.lru.Remove(e)
+	}
+	b := &bucket{key: key, tokens: float64(s.cfg.Burst), last: now}
+	s.buckets[key] = s.lru.PushFront(b)
+	return b
+}
+
+// summarize 输出并清零被抑制条数，同时清理已回满的限流桶
+func (s *sampler) summarize() {
+	now := s.now()
+	s.mu.Lock()
+	suppressed := s.suppressed
+	s.suppressed = make(map[string]uint64)
+	for e := s.lru.Front(); e != nil; {
+		next := e.Next()
+		if b := e.Value.(*bucket); b.tokens+now.Sub(b.last).Seconds()*s.cfg.Rate >= float64(s.cfg.Burst) {
+			delete(s.buckets, b.key)
+			s.lru.Remove(e)
+		}
+		e = next
+	}
+	s.mu.Unlock()
+
+	if len(suppressed) == 0 {
+		return
+	}
+	var total uint64
+	for _, n := range suppressed {
+		total += n
+	}
+	ent := zapcore.Entry{Level: zapcore.WarnLevel, Time: now, Message: SummaryMessage}
+	if ce := s.core.Check(ent, nil); ce != nil {
+		ce.Write(zap.Uint64("suppressed", total), zap.Any("messages", suppressed))
+	}
+}
+
+func (s *sampler) run() {
+	defer close(s.stopped)
+	ticker := time.NewTicker(s.cfg.SummaryInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			s.summarize()
+		case <-s.done:
+			s.summarize()
+			return
+		}
+	}
+}
+
+// close 输出最后的汇总行并停止汇总goroutine
+func (s *sampler) close() error {
+	close(s.done)
+	<-s.stopped
+	return nil
+}
+
+// fieldValue 返回最后一个名为key的字段值的字符串形式
+func fieldValue(fields []zapcore.Field, key string) (string, bool) {
+	for i := len(fields) - 1; i >= 0; i-- {
+		if fields[i].Key != key {
+			continue
+		}
+		enc := zapcore.NewMapObjectEncoder()
+		fields[i].AddTo(enc)
+		if v, ok := enc.Fields[key]; ok {
+			return fmt.Sprint(v), true
+		}
+	}
+	return "", false
+}
+
+// sampleCore 按采样及限流规则过滤日志
+type sampleCore struct {
+	zapcore.Core
+	s     *sampler
+	value string // With添加的限流字段的值
+}
+
+func newSampleCore(core zapcore.Core, cfg SamplingConfig, cs *closers) zapcore.Core {
+	s := newSampler(core, cfg)
+	go s.run()
+	cs.add(s.close)
+	return sampleCore{Core: core, s: s}
+}
+
+func (c sampleCore) With(fields []zapcore.Field) zapcore.Core {
+	child := sampleCore{Core: c.Core.With(fields), s: c.s, value: c.value}
+	if c.s.cfg.RateLimitKey != "" {
+		if v, ok := fieldValue(fields, c.s.cfg.RateLimitKey); ok {
+			child.value = v
+		}
+	}
+	return child
+}
+
+func (c sampleCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Core.Enabled(ent.Level) {
+		return ce
+	}
+	return ce.AddCore(ent, c)
+}
+
+// Write 限流需要字段的值，因此在Write中判断，通过后再交给下层core按级别输出
+func (c sampleCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	value := c.value
+	if c.s.cfg.RateLimitKey != "" {
+		if v, ok := fieldValue(fields, c.s.cfg.RateLimitKey); ok {
+			value = v
+		}
+	}
+	if !c.s.allow(ent, value) {
+		return nil
+	}
+	if ce := c.Core.Check(ent, nil); ce != nil {
+		ce.Write(fields...)
+	}
+	return nil
+}
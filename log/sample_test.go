@@ -0,0 +1,136 @@
+package log
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+	"testing"
+	"time"
+)
+
+// newTestSampleCore 返回使用可控时钟、不启动汇总goroutine的采样core
+func newTestSampleCore(cfg SamplingConfig) (*zap.Logger, *sampler, *observer.ObservedLogs, *time.Time) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	now := time.Date(2026, 10, 18, 0, 0, 0, 0, time.UTC)
+	s := newSampler(core, cfg)
+	s.now = func() time.Time { return now }
+	return zap.New(sampleCore{Core: core, s: s}), s, logs, &now
+}
+
+func TestSampling(t *testing.T) {
+	logger, s, logs, now := newTestSampleCore(SamplingConfig{First: 3, Thereafter: 10})
+	for i := 0; i < 25; i++ {
+		logger.Error("db down")
+	}
+	logger.Info("db down") // 不同级别分别计数
+	// 前3条及第13、23条
+	if n := logs.FilterMessage("db down").Len(); n != 6 {
+		t.Fatalf("%d entries logged, want 6", n)
+	}
+
+	// 下一秒重新计数
+	*now = now.Add(time.Second)
+	logger.Error("db down")
+	if n := logs.Len(); n != 7 {
+		t.Fatalf("%d entries logged after a second, want 7", n)
+	}
+
+	s.summarize()
+	summary := logs.FilterMessage(SummaryMessage).All()
+	if len(summary) != 1 {
+		t.Fatalf("%d summary lines, want 1", len(summary))
+	}
+	fields := summary[0].ContextMap()
+	if fields["suppressed"] != uint64(20) {
+		t.Fatalf("summary %v, want 20 suppressed", fields)
+	}
+	if m, ok := fields["messages"].(map[string]uint64); !ok || m["db down"] != 20 {
+		t.Fatalf("summary messages %v", fields["messages"])
+	}
+
+	// 没有被抑制的日志时不输出汇总行
+	s.summarize()
+	if n := logs.FilterMessage(SummaryMessage).Len(); n != 1 {
+		t.Fatalf("%d summary lines, want 1", n)
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	logger, s, logs, now := newTestSampleCore(SamplingConfig{RateLimitKey: "ip", Rate: 2, Burst: 2})
+	for i := 0; i < 5; i++ {
+		logger.Warn("too many requests", zap.String("ip", "10.0.0.1"))
+		logger.Warn("too many requests", zap.String("ip", "10.0.0.2"))
+	}
+	if n := logs.Len(); n != 4 {
+		t.Fatalf("%d entries logged, want 4", n)
+	}
+
+	// 0.5秒补充1条
+	*now = now.Add(500 * time.Millisecond)
+	logger.Warn("too many requests", zap.String("ip", "10.0.0.1"))
+	logger.Warn("too many requests", zap.String("ip", "10.0.0.1"))
+	if n := logs.FilterField(zap.String("ip", "10.0.0.1")).Len(); n != 3 {
+		t.Fatalf("%d entries logged for 10.0.0.1, want 3", n)
+	}
+
+	s.summarize()
+	summary := logs.FilterMessage(SummaryMessage).All()
+	if len(summary) != 1 || summary[0].ContextMap()["suppressed"] != uint64(7) {
+		t.Fatalf("summary %v, want 7 suppressed", summary)
+	}
+
+	// 回满的限流桶被清理
+	*now = now.Add(time.Minute)
+	s.summarize()
+	if len(s.buckets) != 0 {
+		t.Fatalf("%d buckets left", len(s.buckets))
+	}
+}
+
+func TestRateLimitWith(t *testing.T) {
+	logger, _, logs, _ := newTestSampleCore(SamplingConfig{RateLimitKey: "ip", Rate: 1, Burst: 1})
+	a := logger.With(zap.String("ip", "10.0.0.1"))
+	b := logger.With(zap.String("ip", "10.0.0.2"))
+	for i := 0; i < 3; i++ {
+		a.Warn("too many requests")
+		b.Warn("too many requests")
+		b.Warn("too many requests", zap.String("ip", "10.0.0.3")) // 日志字段优先于With字段
+	}
+	if n := logs.Len(); n != 3 {
+		t.Fatalf("%d entries logged, want 3", n)
+	}
+}
+
+func TestRateLimitMaxBuckets(t *testing.T) {
+	logger, s, logs, _ := newTestSampleCore(SamplingConfig{RateLimitKey: "ip", Rate: 1, Burst: 1, MaxBuckets: 2})
+	for _, ip := range []string{"10.0.0.1", "10.0.0.2", "10.0.0.1", "10.0.0.3"} {
+		logger.Warn("too many requests", zap.String("ip", ip))
+	}
+	if len(s.buckets) != 2 || s.lru.Len() != 2 {
+		t.Fatalf("%d buckets, want 2", len(s.buckets))
+	}
+	// 10.0.0.2最久未使用，已被淘汰，再次出现时使用新的桶
+	logger.Warn("too many requests", zap.String("ip", "10.0.0.2"))
+	logger.Warn("too many requests", zap.String("ip", "10.0.0.3"))
+	if n := logs.FilterField(zap.String("ip", "10.0.0.2")).Len(); n != 2 {
+		t.Fatalf("%d entries logged for 10.0.0.2, want 2", n)
+	}
+	if n := logs.FilterField(zap.String("ip", "10.0.0.3")).Len(); n != 1 {
+		t.Fatalf("%d entries logged for 10.0.0.3, want 1", n)
+	}
+}
+
+func TestSamplingClose(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	cs := &closers{}
+	logger := zap.New(newSampleCore(core, SamplingConfig{First: 1, SummaryInterval: time.Hour}, cs))
+	logger.Info("repeated")
+	logger.Info("repeated")
+	if err := cs.close(); err != nil {
+		t.Fatal(err)
+	}
+	summary := logs.FilterMessage(SummaryMessage).All()
+	if len(summary) != 1 || summary[0].ContextMap()["suppressed"] != uint64(1) {
+		t.Fatalf("summary %v, want 1 suppressed on close", summary)
+	}
+}
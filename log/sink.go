@@ -0,0 +1,275 @@
+package log
+
+import (
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Sink 远程日志输出，New为其创建core并与文件输出并行
+type Sink interface {
+	// Core 返回输出到该sink的core及关闭函数，enc为JSON编码器，已按Config应用脱敏规则；
+	// core不应阻塞在网络上，关闭函数发送待发送的日志并停止后台goroutine，由Close调用
+	Core(enc zapcore.Encoder) (zapcore.Core, func() error)
+}
+
+// _sinkDropped、_sinkFailed 所有远程输出丢弃及发送失败的日志条数
+var _sinkDropped, _sinkFailed uint64
+
+// SinkDropped 返回远程输出因待发送的日志超出上限丢弃的日志条数，不计入Dropped
+func SinkDropped() uint64 {
+	return atomic.LoadUint64(&_sinkDropped)
+}
+
+// SinkFailed 返回远程输出重试后仍发送失败的日志条数
+func SinkFailed() uint64 {
+	return atomic.LoadUint64(&_sinkFailed)
+}
+
+const (
+	dialTimeout    = 5 * time.Second
+	writeTimeout   = 5 * time.Second
+	redialInterval = time.Second // 连接失败后，该间隔内的日志不再连接，直接计入SinkFailed
+)
+
+// netWriter 网络writer，每次写入作为一条消息进入有界队列，由后台goroutine连接并写入，
+// 写入失败时重连并重试一次
+type netWriter struct {
+	network string
+	addr    string
+	queue   chan []byte
+
+	conn    net.Conn // 仅由后台goroutine使用
+	retryAt time.Time
+
+	syncReq chan chan struct{}
+	once    sync.Once
+	done    chan struct{}
+	stopped chan struct{}
+}
+
+func newNetWriter(network, addr string, maxPending int) *netWriter {
+	if maxPending <= 0 {
+		maxPending = 1024
+	}
+	w := &netWriter{
+		network: network,
+		addr:    addr,
+		queue:   make(chan []byte, maxPending),
+		syncReq: make(chan chan struct{}),
+		done:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go w.run()
+	return w
+}
+
+// Write 将p复制到队列，队列满时丢弃并计入SinkDropped
+func (w *netWriter) Write(p []byte) (int, error) {
+	select {
+	case w.queue <- append([]byte(nil), p...):
+	default:
+		atomic.AddUint64(&_sinkDropped, 1)
+	}
+	return len(p), nil
+}
+
+// Sync 发送队列中的日志
+func (w *netWriter) Sync() error {
+	ch := make(chan struct{})
+	select {
+	case w.syncReq <- ch:
+		<-ch
+	case <-w.stopped:
+	}
+	return nil
+}
+
+// Close 发送队列中的日志，关闭连接并停止后台goroutine
+func (w *netWriter) Close() error {
+	w.once.Do(func() {
+		close(w.done)
+	})
+	<-w.stopped
+	return nil
+}
+
+func (w *netWriter) run() {
+	defer close(w.stopped)
+	for {
+		select {
+		case p := <-w.queue:
+			w.send(p)
+		case ch := <-w.syncReq:
+			w.drain()
+			close(ch)
+		case <-w.done:
+			w.drain()
+			if w.conn != nil {
+				w.conn.Close()
+			}
+			return
+		}
+	}
+}
+
+func (w *netWriter) drain() {
+	for {
+		select {
+		case p := <-w.queue:
+			w.send(p)
+		default:
+			return
+		}
+	}
+}
+
+// send 写入一条消息，失败时计入SinkFailed
+func (w *netWriter) send(p []byte) {
+	for i := 0; i < 2; i++ {
+		if w.conn == nil {
+			if time.Now().Before(w.retryAt) {
+				break
+			}
+			conn, err := net.DialTimeout(w.network, w.addr, dialTimeout)
+			if err != nil {
+				w.retryAt = time.Now().Add(redialInterval)
+				break
+			}
+			w.conn = conn
+		}
+		w.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if _, err := w.conn.Write(p); err == nil {
+			return
+		}
+		w.conn.Close()
+		w.conn = nil
+	}
+	atomic.AddUint64(&_sinkFailed, 1)
+}
+
+// TCPSink 以换行分隔的JSON通过TCP输出
+type TCPSink struct {
+	Addr       string
+	MaxPending int // 待发送的最大条数，超出时丢弃并计入SinkDropped，默认1024
+}
+
+func (s TCPSink) Core(enc zapcore.Encoder) (zapcore.Core, func() error) {
+	w := newNetWriter("tcp", s.Addr, s.MaxPending)
+	return zapcore.NewCore(enc, w, zapcore.DebugLevel), w.Close
+}
+
+// SyslogSink 以RFC 5424格式通过UDP或TCP输出到syslog，TCP使用RFC 6587的长度前缀分帧
+type SyslogSink struct {
+	Network    string // udp 或 tcp，默认udp
+	Addr       string
+	Facility   int    // 默认1（user）
+	AppName    string // 默认为程序名
+	Hostname   string // 默认为主机名
+	MaxPending int    // 待发送的最大条数，超出时丢弃并计入SinkDropped，默认1024
+}
+
+func (s SyslogSink) Core(enc zapcore.Encoder) (zapcore.Core, func() error) {
+	if s.Network == "" {
+		s.Network = "udp"
+	}
+	if s.Facility == 0 {
+		s.Facility = 1
+	}
+	if s.AppName == "" {
+		s.AppName = filepath.Base(os.Args[0])
+	}
+	if s.Hostname == "" {
+		s.Hostname, _ = os.Hostname()
+	}
+	w := newNetWriter(s.Network, s.Addr, s.MaxPending)
+	return &syslogCore{
+		LevelEnabler: zapcore.DebugLevel,
+		enc:          enc,
+		w:            w,
+		sink:         s,
+		pid:          os.Getpid(),
+	}, w.Close
+}
+
+type syslogCore struct {
+	zapcore.LevelEnabler
+	enc  zapcore.Encoder
+	w    *netWriter
+	sink SyslogSink
+	pid  int
+}
+
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	clone := *c
+	clone.enc = c.enc.Clone()
+	for i := range fields {
+		fields[i].AddTo(clone.enc)
+	}
+	return &clone
+}
+
+func (c *syslogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *syslogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	defer buf.Free()
+	body := buf.Bytes()
+	if n := len(body); n > 0 && body[n-1] == '\n' {
+		body = body[:n-1]
+	}
+
+	// <PRI>VERSION TIMESTAMP HOSTNAME APP-NAME PROCID MSGID STRUCTURED-DATA MSG
+	msg := fmt.Sprintf("<%d>1 %s %s %s %d - - %s",
+		c.sink.Facility*8+syslogSeverity(ent.Level),
+		ent.Time.Format("2006-01-02T15:04:05.000000Z07:00"),
+		nilValue(c.sink.Hostname), nilValue(c.sink.AppName), c.pid, body)
+	if c.sink.Network != "udp" {
+		msg = fmt.Sprintf("%d %s", len(msg), msg)
+	}
+	_, err = c.w.Write([]byte(msg))
+	return err
+}
+
+func (c *syslogCore) Sync() error {
+	return c.w.Sync()
+}
+
+// syslogSeverity 返回日志级别对应的syslog严重性
+func syslogSeverity(l zapcore.Level) int {
+	switch l {
+	case zapcore.DebugLevel:
+		return 7
+	case zapcore.InfoLevel:
+		return 6
+	case zapcore.WarnLevel:
+		return 4
+	case zapcore.ErrorLevel:
+		return 3
+	case zapcore.DPanicLevel:
+		return 2
+	case zapcore.PanicLevel:
+		return 1
+	}
+	return 0
+}
+
+func nilValue(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
@@ -0,0 +1,306 @@
+package log
+
+import (
+	"bufio"
+	"encoding/json"
+	"go.uber.org/zap"
+	"io"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func newSinkLogger(t *testing.T, sinks ...Sink) (*zap.Logger, func()) {
+	dir, err := ioutil.TempDir("", "log")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg := EnvDevelop.Config()
+	cfg.Dir = dir
+	cfg.Sinks = sinks
+	logger := New(cfg)
+	return logger, func() {
+		Close(logger)
+		os.RemoveAll(dir)
+	}
+}
+
+var syslogRe = regexp.MustCompile(`^<(\d+)>1 \S+ host app \d+ - - (\{.*\})$`)
+
+func TestSyslogSinkUDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	logger, restore := newSinkLogger(t, SyslogSink{Addr: pc.LocalAddr().String(), AppName: "app", Hostname: "host"})
+	defer restore()
+	logger.Warn("disk full", zap.String("mount", "/data"))
+
+	pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	buf := make([]byte, 2048)
+	n, _, err := pc.ReadFrom(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := syslogRe.FindStringSubmatch(string(buf[:n]))
+	if m == nil {
+		t.Fatalf("unexpected syslog message %q", buf[:n])
+	}
+	// user facility (1) * 8 + warning (4)
+	if m[1] != "12" {
+		t.Fatalf("PRI = %s, want 12", m[1])
+	}
+	var body map[string]interface{}
+	if err := json.Unmarshal([]byte(m[2]), &body); err != nil {
+		t.Fatal(err)
+	}
+	if body["M"] != "disk full" || body["mount"] != "/data" {
+		t.Fatalf("unexpected body %v", body)
+	}
+}
+
+func TestSyslogSinkTCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	msgs := make(chan string, 2)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		r := bufio.NewReader(conn)
+		for {
+			// RFC 6587 octet counting: MSG-LEN SP SYSLOG-MSG
+			prefix, err := r.ReadString(' ')
+			if err != nil {
+				return
+			}
+			n, err := strconv.Atoi(strings.TrimSpace(prefix))
+			if err != nil {
+				return
+			}
+			b := make([]byte, n)
+			if _, err := io.ReadFull(r, b); err != nil {
+				return
+			}
+			msgs <- string(b)
+		}
+	}()
+
+	logger, restore := newSinkLogger(t, SyslogSink{Network: "tcp", Addr: ln.Addr().String(), Facility: 16, AppName: "app", Hostname: "host"})
+	defer restore()
+	logger.Error("first")
+	logger.Debug("second")
+
+	for i, want := range []struct{ pri, msg string }{{"131", "first"}, {"135", "second"}} {
+		select {
+		case msg := <-msgs:
+			m := syslogRe.FindStringSubmatch(msg)
+			if m == nil || m[1] != want.pri || !strings.Contains(m[2], want.msg) {
+				t.Fatalf("message %d = %q, want PRI %s with %q", i, msg, want.pri, want.msg)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("message %d not received", i)
+		}
+	}
+}
+
+func TestTCPSink(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	lines := make(chan string, 3)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer conn.Close()
+				s := bufio.NewScanner(conn)
+				for s.Scan() {
+					lines <- s.Text()
+				}
+			}()
+		}
+	}()
+
+	logger, restore := newSinkLogger(t, TCPSink{Addr: ln.Addr().String()})
+	defer restore()
+	logger.Info("one", zap.Int("n", 1))
+	logger.Info("two", zap.Int("n", 2))
+
+	for i := 1; i <= 2; i++ {
+		select {
+		case line := <-lines:
+			var body map[string]interface{}
+			if err := json.Unmarshal([]byte(line), &body); err != nil {
+				t.Fatalf("line %q: %v", line, err)
+			}
+			if body["n"] != float64(i) {
+				t.Fatalf("line %d = %q", i, line)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("line %d not received", i)
+		}
+	}
+}
+
+func TestHTTPSink(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		requests int
+		received []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		defer mu.Unlock()
+		requests++
+		// 第一次请求失败，验证重试
+		if requests == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		if r.Header.Get("Content-Type") != "application/x-ndjson" || r.Header.Get("X-Api-Key") != "k" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		b, _ := ioutil.ReadAll(r.Body)
+		received = append(received, strings.Split(strings.TrimSpace(string(b)), "\n")...)
+	}))
+	defer srv.Close()
+
+	logger, restore := newSinkLogger(t, HTTPSink{
+		URL:           srv.URL,
+		Header:        http.Header{"X-Api-Key": {"k"}},
+		BatchSize:     2,
+		FlushInterval: time.Hour,
+		Backoff:       time.Millisecond,
+	})
+	defer restore()
+	for i := 0; i < 5; i++ {
+		logger.Info("batched", zap.Int("i", i))
+	}
+	if err := logger.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 5 {
+		t.Fatalf("received %d entries, want 5", len(received))
+	}
+	for i, line := range received {
+		var body map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &body); err != nil {
+			t.Fatalf("line %q: %v", line, err)
+		}
+		if body["i"] != float64(i) {
+			t.Fatalf("entry %d out of order: %q", i, line)
+		}
+	}
+	// 5条分3批，另有1次失败重试
+	if requests != 4 {
+		t.Fatalf("%d requests, want 4", requests)
+	}
+}
+
+func TestHTTPSinkNoRetryOnClientError(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+	defer srv.Close()
+
+	w := newHTTPWriter(HTTPSink{URL: srv.URL, FlushInterval: time.Hour, Backoff: time.Millisecond})
+	defer w.Close()
+	failed := SinkFailed()
+	w.Write([]byte("{}\n"))
+	w.Write([]byte("{}\n"))
+	if err := w.Sync(); err == nil {
+		t.Fatal("expected error")
+	}
+	if requests != 1 {
+		t.Fatalf("%d requests, want 1", requests)
+	}
+	if n := SinkFailed() - failed; n != 2 {
+		t.Fatalf("%d entries counted as failed, want 2", n)
+	}
+}
+
+func TestHTTPSinkClose(t *testing.T) {
+	var (
+		mu       sync.Mutex
+		received int
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		b, _ := ioutil.ReadAll(r.Body)
+		mu.Lock()
+		received += strings.Count(string(b), "\n")
+		mu.Unlock()
+	}))
+	defer srv.Close()
+
+	w := newHTTPWriter(HTTPSink{URL: srv.URL, BatchSize: 100, FlushInterval: time.Hour, MaxPending: 2})
+	dropped := SinkDropped()
+	for i := 0; i < 3; i++ {
+		w.Write([]byte("{}\n"))
+	}
+	if n := SinkDropped() - dropped; n != 1 {
+		t.Fatalf("%d entries dropped, want 1", n)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	mu.Lock()
+	defer mu.Unlock()
+	if received != 2 {
+		t.Fatalf("received %d entries after Close, want 2", received)
+	}
+	if err := w.Sync(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestNetWriterUnreachable(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	ln.Close()
+
+	w := newNetWriter("tcp", addr, 0)
+	failed := SinkFailed()
+	for i := 0; i < 3; i++ {
+		if _, err := w.Write([]byte("{}\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 连接失败后不再为每条日志重新连接
+	w.Sync()
+	if n := SinkFailed() - failed; n != 3 {
+		t.Fatalf("%d entries counted as failed, want 3", n)
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
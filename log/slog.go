@@ -0,0 +1,140 @@
+//go:build go1.21
+// +build go1.21
+
+package log
+
+import (
+	"context"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"log/slog"
+	"runtime"
+)
+
+// SlogHandler 由包logger输出的slog.Handler，跟随全局logger替换，并带上context中的字段
+type SlogHandler struct {
+	logger *Logger
+	fields []zap.Field
+}
+
+// NewSlogHandler 返回名称为name的slog.Handler，name为空时使用全局logger
+func NewSlogHandler(name string) *SlogHandler {
+	logger := root
+	if name != "" {
+		logger = Named(name)
+	}
+	return &SlogHandler{logger: logger}
+}
+
+// Slog 返回由NewSlogHandler(name)输出的*slog.Logger
+func Slog(name string) *slog.Logger {
+	return slog.New(NewSlogHandler(name))
+}
+
+func (h *SlogHandler) Enabled(_ context.Context, l slog.Level) bool {
+	return h.logger.L().Core().Enabled(slogLevel(l))
+}
+
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	ce := h.logger.L().Check(slogLevel(r.Level), r.Message)
+	if ce == nil {
+		return nil
+	}
+	if !r.Time.IsZero() {
+		ce.Time = r.Time
+	}
+	// caller取slog的调用方
+	if r.PC != 0 {
+		f, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		ce.Caller = zapcore.NewEntryCaller(f.PC, f.File, f.Line, true)
+	}
+	fields := ContextFields(ctx)
+	fields = append(fields, h.fields...)
+	r.Attrs(func(a slog.Attr) bool {
+		fields = appendSlogAttr(fields, a)
+		return true
+	})
+	ce.Write(fields...)
+	return nil
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := append([]zap.Field(nil), h.fields...)
+	for _, a := range attrs {
+		fields = appendSlogAttr(fields, a)
+	}
+	return &SlogHandler{logger: h.logger, fields: fields}
+}
+
+// WithGroup 之后的字段均输出在name对象中
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	fields := append([]zap.Field(nil), h.fields...)
+	return &SlogHandler{logger: h.logger, fields: append(fields, zap.Namespace(name))}
+}
+
+// slogLevel 返回slog级别对应的日志级别，介于两级之间时取较低的一级
+func slogLevel(l slog.Level) zapcore.Level {
+	switch {
+	case l >= slog.LevelError:
+		return zapcore.ErrorLevel
+	case l >= slog.LevelWarn:
+		return zapcore.WarnLevel
+	case l >= slog.LevelInfo:
+		return zapcore.InfoLevel
+	}
+	return zapcore.DebugLevel
+}
+
+// appendSlogAttr 将slog.Attr转换为字段，忽略空Attr，key为空的组展开到当前层级
+func appendSlogAttr(fields []zap.Field, a slog.Attr) []zap.Field {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+	v := a.Value
+	switch v.Kind() {
+	case slog.KindString:
+		return append(fields, zap.String(a.Key, v.String()))
+	case slog.KindInt64:
+		return append(fields, zap.Int64(a.Key, v.Int64()))
+	case slog.KindUint64:
+		return append(fields, zap.Uint64(a.Key, v.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, zap.Float64(a.Key, v.Float64()))
+	case slog.KindBool:
+		return append(fields, zap.Bool(a.Key, v.Bool()))
+	case slog.KindDuration:
+		return append(fields, zap.Duration(a.Key, v.Duration()))
+	case slog.KindTime:
+		return append(fields, zap.Time(a.Key, v.Time()))
+	case slog.KindGroup:
+		attrs := v.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		if a.Key == "" {
+			for _, ga := range attrs {
+				fields = appendSlogAttr(fields, ga)
+			}
+			return fields
+		}
+		return append(fields, zap.Object(a.Key, slogGroup(attrs)))
+	}
+	return append(fields, zap.Any(a.Key, v.Any()))
+}
+
+type slogGroup []slog.Attr
+
+func (g slogGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	var fields []zap.Field
+	for _, a := range g {
+		fields = appendSlogAttr(fields, a)
+	}
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return nil
+}
@@ -0,0 +1,75 @@
+//go:build go1.21
+// +build go1.21
+
+package log
+
+import (
+	"context"
+	"go.uber.org/zap/zapcore"
+	"log/slog"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSlogHandler(t *testing.T) {
+	logs, restore := observe()
+	defer restore()
+
+	ctx := WithRequestID(context.Background(), "req-1")
+	logger := Slog("api").With("user", "alice").WithGroup("http")
+	logger.WarnContext(ctx, "slow request",
+		"status", 200,
+		slog.Duration("latency", time.Second),
+		slog.Group("client", "ip", "10.0.0.1"),
+		slog.Group("", "inline", true),
+	)
+
+	entries := logs.AllUntimed()
+	if len(entries) != 1 {
+		t.Fatalf("%d entries, want 1", len(entries))
+	}
+	e := entries[0]
+	if e.Level != zapcore.WarnLevel || e.Message != "slow request" || e.LoggerName != "api" {
+		t.Fatalf("unexpected entry %+v", e.Entry)
+	}
+	if file := filepath.Base(e.Caller.File); file != "slog_test.go" {
+		t.Fatalf("caller = %s, want slog_test.go", e.Caller.String())
+	}
+	fields := e.ContextMap()
+	if fields[FieldRequestID] != "req-1" || fields["user"] != "alice" {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+	http, ok := fields["http"].(map[string]interface{})
+	if !ok {
+		t.Fatalf("http group missing: %v", fields)
+	}
+	if http["status"] != int64(200) || http["latency"] != time.Second || http["inline"] != true {
+		t.Fatalf("unexpected http group %v", http)
+	}
+	if client, _ := http["client"].(map[string]interface{}); client["ip"] != "10.0.0.1" {
+		t.Fatalf("unexpected client group %v", http["client"])
+	}
+}
+
+func TestSlogLevel(t *testing.T) {
+	logs, restore := observe()
+	defer restore()
+	SetLevel(zapcore.InfoLevel)
+
+	logger := Slog("")
+	if logger.Enabled(context.Background(), slog.LevelDebug) {
+		t.Fatal("debug enabled at info level")
+	}
+	logger.Debug("hidden")
+	logger.Log(context.Background(), slog.LevelInfo+2, "info+2")
+	logger.Log(context.Background(), slog.LevelError+4, "fatal-ish")
+
+	entries := logs.AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("%d entries, want 2", len(entries))
+	}
+	if entries[0].Level != zapcore.InfoLevel || entries[1].Level != zapcore.ErrorLevel {
+		t.Fatalf("levels = %v, %v", entries[0].Level, entries[1].Level)
+	}
+}
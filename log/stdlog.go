@@ -0,0 +1,35 @@
+package log
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	stdlog "log"
+	"strings"
+)
+
+// RedirectStdLog 将标准库log的输出按level重定向到名称为stdlog的logger，每次输出为一条日志，返回恢复函数
+func RedirectStdLog(level zapcore.Level) func() {
+	flags, prefix, out := stdlog.Flags(), stdlog.Prefix(), stdlog.Writer()
+	stdlog.SetFlags(0)
+	stdlog.SetPrefix("")
+	stdlog.SetOutput(&stdLogWriter{logger: Named("stdlog"), level: level})
+	return func() {
+		stdlog.SetFlags(flags)
+		stdlog.SetPrefix(prefix)
+		stdlog.SetOutput(out)
+	}
+}
+
+type stdLogWriter struct {
+	logger *Logger
+	level  zapcore.Level
+}
+
+func (w *stdLogWriter) Write(p []byte) (int, error) {
+	msg := strings.TrimSuffix(string(p), "\n")
+	// 跳过log.Logger.Output及log.Printf等，caller取标准库log的调用方
+	if ce := w.logger.get().logger.WithOptions(zap.AddCallerSkip(2)).Check(w.level, msg); ce != nil {
+		ce.Write()
+	}
+	return len(p), nil
+}
@@ -0,0 +1,35 @@
+package log
+
+import (
+	"go.uber.org/zap/zapcore"
+	stdlog "log"
+	"path/filepath"
+	"testing"
+)
+
+func TestRedirectStdLog(t *testing.T) {
+	logs, restore := observe()
+	defer restore()
+
+	undo := RedirectStdLog(zapcore.WarnLevel)
+	stdlog.Printf("legacy %d", 1)
+	stdlog.Println("legacy 2")
+	undo()
+	if stdlog.Flags() != stdlog.LstdFlags {
+		t.Fatalf("flags not restored: %d", stdlog.Flags())
+	}
+
+	entries := logs.AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("%d entries, want 2", len(entries))
+	}
+	for i, want := range []string{"legacy 1", "legacy 2"} {
+		e := entries[i]
+		if e.Message != want || e.Level != zapcore.WarnLevel || e.LoggerName != "stdlog" {
+			t.Fatalf("entry %d = %+v", i, e.Entry)
+		}
+		if file := filepath.Base(e.Caller.File); file != "stdlog_test.go" {
+			t.Fatalf("caller = %s, want stdlog_test.go", e.Caller.String())
+		}
+	}
+}
@@ -14,7 +14,7 @@ import (
 	"time"
 )
 
-var logger = log.L()
+var logger = log.Named("mongo")
 
 type MgoConf struct {
 	User        string
@@ -49,7 +49,8 @@ func newClient(cfg *MgoConf, readPreference string) (*mongo.Client, error) {
 		uri = fmt.Sprintf("%sreadPreference=%s&", uri, readPreference)
 	}
 	uri = strings.TrimRight(strings.TrimRight(uri, "?"), "&")
-	client, err := mongo.NewClient(options.Client().ApplyURI(uri).SetMaxPoolSize(cfg.MaxPoolSize))
+	client, err := mongo.NewClient(options.Client().ApplyURI(uri).SetMaxPoolSize(cfg.MaxPoolSize).
+		SetMonitor(CommandMonitor()).SetPoolMonitor(PoolMonitor()))
 	if err != nil {
 		return nil, err
 	}
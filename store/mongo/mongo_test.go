@@ -3,9 +3,14 @@ package mongo
 import (
 	"context"
 	"fmt"
+	"github.com/w3liu/go-common/log"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 	"testing"
 	"time"
 )
@@ -243,3 +248,40 @@ func TestCountEstimateDocuments(t *testing.T) {
 	}
 	t.Log("cnt", cnt)
 }
+
+func TestMonitor(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	old := log.L()
+	log.ReplaceGlobal(zap.New(core))
+	defer log.ReplaceGlobal(old)
+
+	ctx := log.WithRequestID(context.Background(), "req-1")
+	command, err := bson.Marshal(bson.D{{"find", "demo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmd := CommandMonitor()
+	cmd.Started(ctx, &event.CommandStartedEvent{Command: command, DatabaseName: "test", CommandName: "find"})
+	cmd.Failed(ctx, &event.CommandFailedEvent{
+		CommandFinishedEvent: event.CommandFinishedEvent{CommandName: "find", DurationNanos: int64(time.Millisecond)},
+		Failure:              "timeout",
+	})
+	PoolMonitor().Event(&event.PoolEvent{Type: event.GetFailed, Address: "127.0.0.1:27017", Reason: event.ReasonTimedOut})
+
+	entries := logs.AllUntimed()
+	if len(entries) != 3 {
+		t.Fatalf("%d entries, want 3", len(entries))
+	}
+	for i, want := range []struct {
+		msg   string
+		level zapcore.Level
+	}{{"command started", zapcore.DebugLevel}, {"command failed", zapcore.ErrorLevel}, {event.GetFailed, zapcore.WarnLevel}} {
+		e := entries[i]
+		if e.Message != want.msg || e.Level != want.level || e.LoggerName != "mongo" {
+			t.Fatalf("entry %d = %+v", i, e.Entry)
+		}
+	}
+	if fields := entries[1].ContextMap(); fields["failure"] != "timeout" || fields[log.FieldRequestID] != "req-1" {
+		t.Fatalf("unexpected fields %v", fields)
+	}
+}
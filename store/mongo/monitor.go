@@ -0,0 +1,60 @@
+package mongo
+
+import (
+	"context"
+	"github.com/w3liu/go-common/log"
+	"go.mongodb.org/mongo-driver/event"
+	"go.uber.org/zap"
+	"time"
+)
+
+// CommandMonitor 返回将命令输出到mongo logger的监视器，开始及成功为debug级别，失败为error级别
+func CommandMonitor() *event.CommandMonitor {
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			// 命令内容较大，仅在debug开启时序列化
+			if ce := logger.L().Check(zap.DebugLevel, "command started"); ce != nil {
+				ce.Write(append(log.ContextFields(ctx),
+					zap.String("db", e.DatabaseName),
+					zap.String("command_name", e.CommandName),
+					zap.Int64("driver_request_id", e.RequestID),
+					zap.String("connection_id", e.ConnectionID),
+					zap.String("command", e.Command.String()))...)
+			}
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			logger.Debug("command succeeded", append(log.ContextFields(ctx),
+				zap.String("command_name", e.CommandName),
+				zap.Int64("driver_request_id", e.RequestID),
+				zap.Duration("duration", time.Duration(e.DurationNanos)))...)
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			logger.Error("command failed", append(log.ContextFields(ctx),
+				zap.String("command_name", e.CommandName),
+				zap.Int64("driver_request_id", e.RequestID),
+				zap.Duration("duration", time.Duration(e.DurationNanos)),
+				zap.String("failure", e.Failure))...)
+		},
+	}
+}
+
+// PoolMonitor 返回将连接池事件输出到mongo logger的监视器，获取连接失败及连接池清空为warn级别，其余为debug级别
+func PoolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			fields := []zap.Field{
+				zap.String("address", e.Address),
+				zap.Uint64("connection_id", e.ConnectionID),
+			}
+			if e.Reason != "" {
+				fields = append(fields, zap.String("reason", e.Reason))
+			}
+			switch e.Type {
+			case event.GetFailed, event.PoolCleared:
+				logger.Warn(e.Type, fields...)
+			default:
+				logger.Debug(e.Type, fields...)
+			}
+		},
+	}
+}
@@ -0,0 +1,75 @@
+package mysql
+
+import (
+	"fmt"
+	"github.com/w3liu/go-common/log"
+	"go.uber.org/zap"
+	xormlog "xorm.io/xorm/log"
+)
+
+var _ xormlog.ContextLogger = (*XormLogger)(nil)
+
+// XormLogger 将xorm的日志输出到mysql logger，开启ShowSQL时每条SQL输出一条日志，执行出错时为error级别
+type XormLogger struct {
+	level   xormlog.LogLevel
+	showSQL bool
+}
+
+// NewXormLogger 创建xorm logger，通过engine.SetLogger使用
+func NewXormLogger() *XormLogger {
+	return &XormLogger{level: xormlog.LOG_DEBUG}
+}
+
+func (l *XormLogger) BeforeSQL(xormlog.LogContext) {}
+
+func (l *XormLogger) AfterSQL(ctx xormlog.LogContext) {
+	fields := append(log.ContextFields(ctx.Ctx),
+		zap.String("sql", ctx.SQL),
+		zap.Any("args", ctx.Args),
+		zap.Duration("duration", ctx.ExecuteTime))
+	if ctx.Err != nil {
+		logger.Error("sql", append(fields, zap.Error(ctx.Err))...)
+		return
+	}
+	logger.Info("sql", fields...)
+}
+
+func (l *XormLogger) Debugf(format string, v ...interface{}) {
+	if l.level <= xormlog.LOG_DEBUG {
+		logger.Debug(fmt.Sprintf(format, v...))
+	}
+}
+
+func (l *XormLogger) Infof(format string, v ...interface{}) {
+	if l.level <= xormlog.LOG_INFO {
+		logger.Info(fmt.Sprintf(format, v...))
+	}
+}
+
+func (l *XormLogger) Warnf(format string, v ...interface{}) {
+	if l.level <= xormlog.LOG_WARNING {
+		logger.Warn(fmt.Sprintf(format, v...))
+	}
+}
+
+func (l *XormLogger) Errorf(format string, v ...interface{}) {
+	if l.level <= xormlog.LOG_ERR {
+		logger.Error(fmt.Sprintf(format, v...))
+	}
+}
+
+func (l *XormLogger) Level() xormlog.LogLevel {
+	return l.level
+}
+
+func (l *XormLogger) SetLevel(level xormlog.LogLevel) {
+	l.level = level
+}
+
+func (l *XormLogger) ShowSQL(show ...bool) {
+	l.showSQL = len(show) == 0 || show[0]
+}
+
+func (l *XormLogger) IsShowSQL() bool {
+	return l.showSQL
+}
@@ -9,6 +9,8 @@ import (
 	"xorm.io/xorm"
 )
 
+var logger = log.Named("mysql")
+
 type Conf struct {
 	HostPort string
 	Username string
@@ -40,6 +42,7 @@ func NewStore(cfg *Conf) *Store {
 	}
 	engine.SetMaxOpenConns(cfg.MaxConns)
 	engine.SetMaxIdleConns(cfg.MaxIdle)
+	engine.SetLogger(NewXormLogger())
 	engine.ShowSQL(cfg.ShowSQL)
 	engine.SetConnMaxLifetime(time.Hour * 2)
 
@@ -55,6 +58,7 @@ func NewQuery(cfg *Conf) *Query {
 	}
 	engine.SetMaxOpenConns(cfg.MaxConns)
 	engine.SetMaxIdleConns(cfg.MaxIdle)
+	engine.SetLogger(NewXormLogger())
 	engine.ShowSQL(cfg.ShowSQL)
 	engine.SetConnMaxLifetime(time.Hour * 2)
 
@@ -68,7 +72,7 @@ func Execute(trans Transaction, fn func() error) error {
 	defer trans.GetSession().Close()
 	e := fn()
 	if e != nil {
-		log.Error("trans error", zap.Error(e))
+		logger.Error("trans error", zap.Error(e))
 		_ = trans.GetSession().Rollback()
 		return e
 	}
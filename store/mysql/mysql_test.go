@@ -1,8 +1,16 @@
 package mysql
 
 import (
+	"context"
+	"errors"
+	"github.com/w3liu/go-common/log"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
 	"testing"
 	"time"
+	"xorm.io/xorm/contexts"
+	xormlog "xorm.io/xorm/log"
 )
 
 type TestUser struct {
@@ -45,3 +53,32 @@ func TestSync2(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestXormLogger(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	old := log.L()
+	log.ReplaceGlobal(zap.New(core))
+	defer log.ReplaceGlobal(old)
+
+	l := NewXormLogger()
+	l.SetLevel(xormlog.LOG_WARNING)
+	l.Infof("hidden %d", 1)
+	l.Warnf("shown %d", 2)
+
+	ctx := log.WithRequestID(context.Background(), "req-1")
+	hook := contexts.NewContextHook(ctx, "SELECT * FROM user WHERE id = ?", []interface{}{1})
+	hook.End(ctx, nil, errors.New("timeout"))
+	l.AfterSQL(xormlog.LogContext(*hook))
+
+	entries := logs.AllUntimed()
+	if len(entries) != 2 {
+		t.Fatalf("%d entries, want 2", len(entries))
+	}
+	if entries[0].Message != "shown 2" || entries[0].LoggerName != "mysql" {
+		t.Fatalf("unexpected entry %+v", entries[0].Entry)
+	}
+	fields := entries[1].ContextMap()
+	if entries[1].Level != zapcore.ErrorLevel || fields["sql"] != hook.SQL || fields["error"] != "timeout" || fields[log.FieldRequestID] != "req-1" {
+		t.Fatalf("unexpected sql entry %+v %v", entries[1].Entry, fields)
+	}
+}
@@ -0,0 +1,141 @@
+package test
+
+import (
+	"bytes"
+	"github.com/w3liu/go-common/crypto/secp256k1"
+	"github.com/w3liu/go-common/crypto/secp256k1/address"
+	"strings"
+	"testing"
+)
+
+func TestEthereumAddress(t *testing.T) {
+	priv := privKeyFromHex(t, "0000000000000000000000000000000000000000000000000000000000000001")
+	pub := (*secp256k1.PublicKey)(&priv.PublicKey)
+	want := "0x7E5F4552091A69125d5DfCb7b8C2659029395Bdf"
+	if got := address.EthereumAddress(pub); got != want {
+		t.Fatalf("address %s, want %s", got, want)
+	}
+}
+
+func TestParseEthereumAddress(t *testing.T) {
+	// EIP-55 test vectors.
+	valid := []string{
+		"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAed",
+		"0xfB6916095ca1df60bB79Ce92cE3Ea74c37c5d359",
+		"0xdbF03B407c01E7cD3CBea99509d93f8DDDC8C6FB",
+		"0xD1220A0cf47c7B9Be7A2E6BA89F429762e7b9aDb",
+		"0x52908400098527886E0F7030069857D2E4169EE7",
+		"0xde709f2102306220921060314715629080e2fb77",
+		"5aaeb6053f3e94c9b9a09f33669435e7ef1beaed",
+	}
+	for _, s := range valid {
+		addr, err := address.ParseEthereumAddress(s)
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		if len(addr) != address.EthereumAddressLen {
+			t.Fatalf("%s: length %d", s, len(addr))
+		}
+	}
+
+	tests := []struct {
+		addr string
+		err  error
+	}{
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAeD", address.ErrChecksum},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeA", address.ErrInvalidAddress},
+		{"0x5aAeb6053F3E94C9b9A09f33669435E7Ef1BeAzz", address.ErrInvalidAddress},
+	}
+	for _, test := range tests {
+		if _, err := address.ParseEthereumAddress(test.addr); err != test.err {
+			t.Fatalf("%s: err %v, want %v", test.addr, err, test.err)
+		}
+	}
+}
+
+func TestP2PKHAddress(t *testing.T) {
+	priv := privKeyFromHex(t, "0000000000000000000000000000000000000000000000000000000000000001")
+	pub := (*secp256k1.PublicKey)(&priv.PublicKey)
+	tests := []struct {
+		compressed bool
+		want       string
+	}{
+		{true, "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMH"},
+		{false, "1EHNa6Q4Jz2uvNExL497mE43ikXhwF6kZm"},
+	}
+	for _, test := range tests {
+		got := address.P2PKHAddress(pub, test.compressed, address.MainNet)
+		if got != test.want {
+			t.Fatalf("address %s, want %s", got, test.want)
+		}
+		hash, err := address.DecodeP2PKHAddress(got, address.MainNet)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var serialized []byte
+		if test.compressed {
+			serialized = pub.SerializeCompressed()
+		} else {
+			serialized = pub.SerializeUncompressed()
+		}
+		if !bytes.Equal(hash, address.Hash160(serialized)) {
+			t.Fatalf("%s: decoded hash mismatch", got)
+		}
+		if _, err := address.DecodeP2PKHAddress(got, address.TestNet); err != address.ErrInvalidAddress {
+			t.Fatalf("%s: decoded on wrong network: %v", got, err)
+		}
+	}
+
+	corrupted := "1BgGZ9tcN4rm9KBzDn7KprQz87SZ26SAMJ"
+	if _, err := address.DecodeP2PKHAddress(corrupted, address.MainNet); err != address.ErrChecksum {
+		t.Fatalf("corrupted address: err %v, want %v", err, address.ErrChecksum)
+	}
+	if _, err := address.DecodeP2PKHAddress("1BgGZ9tcN4rm9KBzDn7Kpr0z87SZ26SAMH", address.MainNet); err != address.ErrInvalidAddress {
+		t.Fatalf("invalid character: err %v, want %v", err, address.ErrInvalidAddress)
+	}
+}
+
+func TestP2WPKHAddress(t *testing.T) {
+	priv := privKeyFromHex(t, "0000000000000000000000000000000000000000000000000000000000000001")
+	pub := (*secp256k1.PublicKey)(&priv.PublicKey)
+
+	// BIP-173 examples for the compressed generator point.
+	tests := []struct {
+		net  *address.Network
+		want string
+	}{
+		{address.MainNet, "bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t4"},
+		{address.TestNet, "tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx"},
+	}
+	for _, test := range tests {
+		got, err := address.P2WPKHAddress(pub, test.net)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != test.want {
+			t.Fatalf("address %s, want %s", got, test.want)
+		}
+		hash, err := address.DecodeP2WPKHAddress(strings.ToUpper(got), test.net)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(hash, address.Hash160(pub.SerializeCompressed())) {
+			t.Fatalf("%s: decoded hash mismatch", got)
+		}
+	}
+
+	tests2 := []struct {
+		addr string
+		err  error
+	}{
+		{"bc1qw508d6qejxtdg4y5r3zarvary0c5xw7kv8f3t5", address.ErrChecksum},
+		{"tb1qw508d6qejxtdg4y5r3zarvary0c5xw7kxpjzsx", address.ErrInvalidAddress},
+		{"bc1qw508d6qejxtdg4y5r3zarvaRy0c5xw7kv8f3t4", address.ErrInvalidAddress},
+		{"bc1zw508d6qejxtdg4y5r3zarvaryvqyzf3du", address.ErrInvalidAddress},
+	}
+	for _, test := range tests2 {
+		if _, err := address.DecodeP2WPKHAddress(test.addr, address.MainNet); err != test.err {
+			t.Fatalf("%s: err %v, want %v", test.addr, err, test.err)
+		}
+	}
+}
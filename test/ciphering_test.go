@@ -0,0 +1,76 @@
+package test
+
+import (
+	"bytes"
+	"github.com/w3liu/go-common/crypto/secp256k1"
+	"math/big"
+	"testing"
+)
+
+func TestGenerateSharedSecret(t *testing.T) {
+	priv1, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	priv2, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret1, err := secp256k1.GenerateSharedSecret(priv1, priv2.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret2, err := secp256k1.GenerateSharedSecret(priv2, priv1.PubKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(secret1) != 32 || !bytes.Equal(secret1, secret2) {
+		t.Fatalf("secrets differ: %x %x", secret1, secret2)
+	}
+
+	invalid := []*secp256k1.PublicKey{
+		{Curve: secp256k1.S256(), X: new(big.Int), Y: new(big.Int)},
+		{Curve: secp256k1.S256(), X: big.NewInt(1), Y: big.NewInt(1)},
+	}
+	for i, pub := range invalid {
+		if _, err := secp256k1.GenerateSharedSecret(priv1, pub); err != secp256k1.ErrInvalidPubKey {
+			t.Fatalf("#%d: err %v", i, err)
+		}
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	priv, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := []byte("hello secp256k1")
+	ciphertext, err := secp256k1.Encrypt(priv.PubKey(), data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := secp256k1.Decrypt(priv, ciphertext)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(plaintext, data) {
+		t.Fatalf("decrypted %q", plaintext)
+	}
+
+	// Tampering and the wrong key must both fail authentication.
+	ciphertext[len(ciphertext)-1] ^= 0x01
+	if _, err := secp256k1.Decrypt(priv, ciphertext); err != secp256k1.ErrInvalidCiphertext {
+		t.Fatalf("tampered: err %v", err)
+	}
+	ciphertext[len(ciphertext)-1] ^= 0x01
+	other, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := secp256k1.Decrypt(other, ciphertext); err != secp256k1.ErrInvalidCiphertext {
+		t.Fatalf("wrong key: err %v", err)
+	}
+	if _, err := secp256k1.Decrypt(priv, ciphertext[:10]); err != secp256k1.ErrInvalidCiphertext {
+		t.Fatalf("short: err %v", err)
+	}
+}
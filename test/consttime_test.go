@@ -0,0 +1,114 @@
+package test
+
+import (
+	"crypto/rand"
+	"github.com/w3liu/go-common/crypto/secp256k1"
+	"math/big"
+	"testing"
+)
+
+// scalarMultScalars returns edge case scalars followed by random ones.
+func scalarMultScalars(t *testing.T) [][]byte {
+	curve := secp256k1.S256()
+	nMinus1 := new(big.Int).Sub(curve.N, big.NewInt(1))
+	scalars := [][]byte{
+		{},
+		{0x01},
+		{0x02},
+		{0x10},
+		nMinus1.Bytes(),
+		curve.N.Bytes(),
+		decodeHex(t, "ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"),
+		decodeHex(t, "000000000000000000000000000000000000000000000000000000000000000f"),
+		decodeHex(t, "01ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff"),
+	}
+	for i := 0; i < 20; i++ {
+		k := make([]byte, 32)
+		if _, err := rand.Read(k); err != nil {
+			t.Fatal(err)
+		}
+		scalars = append(scalars, k)
+	}
+	return scalars
+}
+
+func TestScalarBaseMultConstantTime(t *testing.T) {
+	curve := secp256k1.S256()
+	for _, k := range scalarMultScalars(t) {
+		wantX, wantY := curve.ScalarBaseMult(k)
+		gotX, gotY := curve.ScalarBaseMultConstantTime(k)
+		if gotX.Cmp(wantX) != 0 || gotY.Cmp(wantY) != 0 {
+			t.Fatalf("k=%x: got (%x, %x), want (%x, %x)", k, gotX, gotY, wantX, wantY)
+		}
+	}
+}
+
+func TestScalarMultConstantTime(t *testing.T) {
+	curve := secp256k1.S256()
+	px, py := curve.ScalarBaseMult(decodeHex(t, "6b2d7a4f4a8e1c11d5e5a2b6c3bd34e5ab9d85ec3b5f3e2f6a9a6c2e1df0b3a1"))
+	points := [][2]*big.Int{{curve.Gx, curve.Gy}, {px, py}}
+	for _, p := range points {
+		for _, k := range scalarMultScalars(t) {
+			wantX, wantY := curve.ScalarMult(p[0], p[1], k)
+			gotX, gotY := curve.ScalarMultConstantTime(p[0], p[1], k)
+			if gotX.Cmp(wantX) != 0 || gotY.Cmp(wantY) != 0 {
+				t.Fatalf("k=%x: got (%x, %x), want (%x, %x)", k, gotX, gotY, wantX, wantY)
+			}
+		}
+	}
+}
+
+func TestNewPrivateKeyConstantTime(t *testing.T) {
+	curve := secp256k1.S256()
+	key, err := secp256k1.NewPrivateKey(curve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.D.Sign() <= 0 || key.D.Cmp(curve.N) >= 0 {
+		t.Fatalf("private key out of range: %x", key.D)
+	}
+	x, y := curve.ScalarBaseMult(key.D.Bytes())
+	if key.X.Cmp(x) != 0 || key.Y.Cmp(y) != 0 {
+		t.Fatal("public key does not match private key")
+	}
+}
+
+var benchScalar = []byte{
+	0x9e, 0x5a, 0x3c, 0x71, 0x0b, 0x2f, 0x8d, 0x46,
+	0xe1, 0x27, 0x6b, 0xd4, 0x53, 0x98, 0xa0, 0x1c,
+	0x3f, 0xc6, 0x72, 0x5e, 0x84, 0x0d, 0xb9, 0x2a,
+	0x67, 0xf1, 0x4e, 0x93, 0x15, 0xac, 0xd8, 0x30,
+}
+
+func BenchmarkScalarBaseMult(b *testing.B) {
+	curve := secp256k1.S256()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		curve.ScalarBaseMult(benchScalar)
+	}
+}
+
+func BenchmarkScalarBaseMultConstantTime(b *testing.B) {
+	curve := secp256k1.S256()
+	curve.ScalarBaseMultConstantTime(benchScalar)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		curve.ScalarBaseMultConstantTime(benchScalar)
+	}
+}
+
+func BenchmarkScalarMult(b *testing.B) {
+	curve := secp256k1.S256()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		curve.ScalarMult(curve.Gx, curve.Gy, benchScalar)
+	}
+}
+
+func BenchmarkScalarMultConstantTime(b *testing.B) {
+	curve := secp256k1.S256()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		curve.ScalarMultConstantTime(curve.Gx, curve.Gy, benchScalar)
+	}
+}
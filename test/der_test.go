@@ -0,0 +1,81 @@
+package test
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"github.com/w3liu/go-common/crypto/secp256k1"
+	"math/big"
+	"testing"
+)
+
+func TestSignatureSerializeDER(t *testing.T) {
+	key := privKeyFromHex(t, "0000000000000000000000000000000000000000000000000000000000000001")
+	hash := sha256.Sum256([]byte("Satoshi Nakamoto"))
+	sig, err := (*secp256k1.PrivateKey)(key).Sign(hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := decodeHex(t, "3045022100934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d802202442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e5")
+	der := sig.Serialize()
+	if !bytes.Equal(der, want) {
+		t.Fatalf("serialized %x, want %x", der, want)
+	}
+	parsed, err := secp256k1.ParseDERSignature(der)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !parsed.IsEqual(sig) || !parsed.Verify(hash[:], &key.PublicKey) {
+		t.Fatal("parsed signature does not match")
+	}
+}
+
+func TestParseDERSignatureMalleability(t *testing.T) {
+	const (
+		r = "934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d8"
+		s = "2442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e5"
+		// N - s
+		highS = "dbbd3162d46e9f9bef7feb87c16dc13b4f6568a87f4e83f728e2443ba586675c"
+	)
+	tests := []struct {
+		name    string
+		sig     string
+		strict  bool // valid for ParseDERSignature
+		lenient bool // valid for ParseSignature
+	}{
+		{"canonical", "3045022100" + r + "0220" + s, true, true},
+		{"high s", "3046022100" + r + "022100" + highS, false, true},
+		{"excess r padding", "304602220000" + r + "0220" + s, false, true},
+		{"excess s padding", "3046022100" + r + "022100" + s, false, true},
+		{"negative r", "30440220" + r + "0220" + s, false, true},
+		{"trailing data", "3045022100" + r + "0220" + s + "01", false, true},
+		{"oversize length", "3046022100" + r + "0220" + s, false, false},
+		{"bogus r length", "30450221ff" + r + "0220" + s, false, false},
+		{"zero s", "3026022100" + r + "020100", false, false},
+		{"no header magic", "3145022100" + r + "0220" + s, false, false},
+		{"too short", "300602010102", false, false},
+	}
+	for _, test := range tests {
+		b := decodeHex(t, test.sig)
+		if _, err := secp256k1.ParseDERSignature(b); (err == nil) != test.strict {
+			t.Errorf("%s: strict parse error %v", test.name, err)
+		}
+		if _, err := secp256k1.ParseSignature(b); (err == nil) != test.lenient {
+			t.Errorf("%s: lenient parse error %v", test.name, err)
+		}
+	}
+}
+
+func TestSerializeLowersS(t *testing.T) {
+	curve := secp256k1.S256()
+	sig := &secp256k1.Signature{
+		R: big.NewInt(1),
+		S: new(big.Int).Sub(curve.N, big.NewInt(1)),
+	}
+	parsed, err := secp256k1.ParseDERSignature(sig.Serialize())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.S.Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("serialized s %v, want 1", parsed.S)
+	}
+}
@@ -0,0 +1,175 @@
+package test
+
+import (
+	"encoding/hex"
+	"github.com/w3liu/go-common/crypto/secp256k1/hdkey"
+	"testing"
+)
+
+func TestHDKeyDerivePath(t *testing.T) {
+	// BIP-32 test vector 1.
+	seed := decodeHex(t, "000102030405060708090a0b0c0d0e0f")
+	tests := []struct {
+		path     string
+		wantPub  string
+		wantPriv string
+	}{
+		{
+			"m",
+			"xpub661MyMwAqRbcFtXgS5sYJABqqG9YLmC4Q1Rdap9gSE8NqtwybGhePY2gZ29ESFjqJoCu1Rupje8YtGqsefD265TMg7usUDFdp6W1EGMcet8",
+			"xprv9s21ZrQH143K3QTDL4LXw2F7HEK3wJUD2nW2nRk4stbPy6cq3jPPqjiChkVvvNKmPGJxWUtg6LnF5kejMRNNU3TGtRBeJgk33yuGBxrMPHi",
+		},
+		{
+			"m/0'",
+			"xpub68Gmy5EdvgibQVfPdqkBBCHxA5htiqg55crXYuXoQRKfDBFA1WEjWgP6LHhwBZeNK1VTsfTFUHCdrfp1bgwQ9xv5ski8PX9rL2dZXvgGDnw",
+			"xprv9uHRZZhk6KAJC1avXpDAp4MDc3sQKNxDiPvvkX8Br5ngLNv1TxvUxt4cV1rGL5hj6KCesnDYUhd7oWgT11eZG7XnxHrnYeSvkzY7d2bhkJ7",
+		},
+		{
+			"m/0H/1",
+			"xpub6ASuArnXKPbfEwhqN6e3mwBcDTgzisQN1wXN9BJcM47sSikHjJf3UFHKkNAWbWMiGj7Wf5uMash7SyYq527Hqck2AxYysAA7xmALppuCkwQ",
+			"xprv9wTYmMFdV23N2TdNG573QoEsfRrWKQgWeibmLntzniatZvR9BmLnvSxqu53Kw1UmYPxLgboyZQaXwTCg8MSY3H2EU4pWcQDnRnrVA1xe8fs",
+		},
+		{
+			"m/0'/1/2'/2/1000000000",
+			"xpub6H1LXWLaKsWFhvm6RVpEL9P4KfRZSW7abD2ttkWP3SSQvnyA8FSVqNTEcYFgJS2UaFcxupHiYkro49S8yGasTvXEYBVPamhGW6cFJodrTHy",
+			"xprvA41z7zogVVwxVSgdKUHDy1SKmdb533PjDz7J6N6mV6uS3ze1ai8FHa8kmHScGpWmj4WggLyQjgPie1rFSruoUihUZREPSL39UNdE3BBDu76",
+		},
+	}
+
+	master, err := hdkey.NewMaster(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, test := range tests {
+		key, err := master.DerivePath(test.path)
+		if err != nil {
+			t.Fatalf("%s: %v", test.path, err)
+		}
+		if got := key.String(); got != test.wantPriv {
+			t.Fatalf("%s: xprv %s, want %s", test.path, got, test.wantPriv)
+		}
+		if got := key.Neuter().String(); got != test.wantPub {
+			t.Fatalf("%s: xpub %s, want %s", test.path, got, test.wantPub)
+		}
+
+		parsed, err := hdkey.NewKeyFromString(test.wantPriv)
+		if err != nil {
+			t.Fatalf("%s: %v", test.path, err)
+		}
+		if parsed.String() != test.wantPriv || parsed.Depth() != key.Depth() {
+			t.Fatalf("%s: parsed key differs", test.path)
+		}
+	}
+}
+
+func TestHDKeyPublicDerivation(t *testing.T) {
+	master, err := hdkey.NewMaster(decodeHex(t, "000102030405060708090a0b0c0d0e0f"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	account, err := master.DerivePath("m/44'/60'/0'")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Non-hardened children of the public key match the public keys of the
+	// private children.
+	priv, err := account.DerivePath("0/7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub, err := account.Neuter().DerivePath("0/7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if priv.Neuter().String() != pub.String() {
+		t.Fatal("public derivation differs from private derivation")
+	}
+	if _, err := pub.ECPrivKey(); err != hdkey.ErrNotPrivExtKey {
+		t.Fatalf("err %v", err)
+	}
+	if _, err := account.Neuter().Derive(hdkey.HardenedKeyStart); err != hdkey.ErrDeriveHardFromPublic {
+		t.Fatalf("err %v", err)
+	}
+
+	for _, path := range []string{"m/", "m/x", "m/1/-1", "m/2147483648"} {
+		if _, err := hdkey.ParsePath(path); err != hdkey.ErrInvalidPath {
+			t.Fatalf("%s: err %v", path, err)
+		}
+	}
+}
+
+func TestMnemonic(t *testing.T) {
+	// BIP-39 test vectors from Trezor, using the passphrase "TREZOR".
+	tests := []struct {
+		entropy  string
+		mnemonic string
+		seed     string
+	}{
+		{
+			"00000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		},
+		{
+			"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+			"legal winner thank year wave sausage worth useful legal winner thank yellow",
+			"2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+		},
+		{
+			"ffffffffffffffffffffffffffffffffffffffffffffffff",
+			"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo when",
+			"0cd6e5d827bb62eb8fc1e262254223817fd068a74b5b449cc2f667c3f1f985a76379b43348d952e2265b4cd129090758b3e3c2c49103b5051aac2eaeb890a528",
+		},
+		{
+			"0000000000000000000000000000000000000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art",
+			"bda85446c68413707090a52022edd26a1c9462295029f2e60cd7c4f2bbd3097170af7a4d73245cafa9c3cca8d561a7c3de6f5d4a10be8ed2a5e608d68f92fcc8",
+		},
+	}
+	for _, test := range tests {
+		mnemonic, err := hdkey.NewMnemonic(decodeHex(t, test.entropy))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if mnemonic != test.mnemonic {
+			t.Fatalf("mnemonic %q, want %q", mnemonic, test.mnemonic)
+		}
+		entropy, err := hdkey.EntropyFromMnemonic(mnemonic)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(entropy) != test.entropy {
+			t.Fatalf("entropy %x, want %s", entropy, test.entropy)
+		}
+		if seed := hdkey.NewSeed(mnemonic, "TREZOR"); hex.EncodeToString(seed) != test.seed {
+			t.Fatalf("seed %x, want %s", seed, test.seed)
+		}
+	}
+
+	invalid := []string{
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon aboutt",
+	}
+	for _, mnemonic := range invalid {
+		if hdkey.IsMnemonicValid(mnemonic) {
+			t.Fatalf("%q: expected invalid", mnemonic)
+		}
+	}
+
+	entropy, err := hdkey.NewEntropy(256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mnemonic, err := hdkey.NewMnemonic(entropy)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !hdkey.IsMnemonicValid(mnemonic) {
+		t.Fatalf("%q: expected valid", mnemonic)
+	}
+	if _, err := hdkey.NewMaster(hdkey.NewSeed(mnemonic, "")); err != nil {
+		t.Fatal(err)
+	}
+}
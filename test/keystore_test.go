@@ -0,0 +1,120 @@
+package test
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"github.com/w3liu/go-common/crypto/secp256k1"
+	"github.com/w3liu/go-common/crypto/secp256k1/keystore"
+	"testing"
+)
+
+// Web3 Secret Storage test vectors.
+var keystoreTestVectors = []struct {
+	name    string
+	keyJSON string
+}{
+	{
+		"pbkdf2",
+		`{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"6087dab2f9fdbbfaddc31a909735c1e6"},"ciphertext":"5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46","kdf":"pbkdf2","kdfparams":{"c":262144,"dklen":32,"prf":"hmac-sha256","salt":"ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"},"mac":"517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"},"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`,
+	},
+	{
+		"scrypt",
+		`{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"83dbcc02d8ccb40e466191a123791e0e"},"ciphertext":"d172bf743a674da9cdad04534d56926ef8358534d458fffccd4e6ad2fbde479c","kdf":"scrypt","kdfparams":{"dklen":32,"n":262144,"p":8,"r":1,"salt":"ab0c7876052600dd703518d6fc3fe8984592145b591fc8fb5c6d43190334ba19"},"mac":"2103ac29920d71da29f15d75b4a16dbe95cfd7ff8faea1056c33131d846e3097"},"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`,
+	},
+}
+
+func TestKeystoreImport(t *testing.T) {
+	want := "7a28b5ba57c53603b0b07b56bba752f7784bf506fa95edc395f5cf6c7514fe9d"
+	for _, test := range keystoreTestVectors {
+		key, err := keystore.Import([]byte(test.keyJSON), "testpassword")
+		if err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		if got := hex.EncodeToString(key.Serialize()); got != want {
+			t.Fatalf("%s: key %s, want %s", test.name, got, want)
+		}
+		if _, err := keystore.Import([]byte(test.keyJSON), "wrong"); err != keystore.ErrDecrypt {
+			t.Fatalf("%s: err %v, want %v", test.name, err, keystore.ErrDecrypt)
+		}
+	}
+}
+
+func TestKeystoreExport(t *testing.T) {
+	key, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	light := keystore.Options{KDF: keystore.KDFScrypt, ScryptN: 1 << 10, ScryptP: 1}
+	fast := keystore.Options{KDF: keystore.KDFPBKDF2, PBKDF2Iterations: 1024}
+	for _, opts := range []keystore.Options{light, fast} {
+		keyJSON, err := keystore.Export(key, "foo", opts)
+		if err != nil {
+			t.Fatalf("%s: %v", opts.KDF, err)
+		}
+		var m map[string]interface{}
+		if err := json.Unmarshal(keyJSON, &m); err != nil {
+			t.Fatal(err)
+		}
+		if m["version"] != float64(3) || m["id"] == "" {
+			t.Fatalf("%s: unexpected json %s", opts.KDF, keyJSON)
+		}
+
+		imported, err := keystore.Import(keyJSON, "foo")
+		if err != nil {
+			t.Fatalf("%s: %v", opts.KDF, err)
+		}
+		if imported.D.Cmp(key.D) != 0 {
+			t.Fatalf("%s: imported key differs", opts.KDF)
+		}
+
+		changed, err := keystore.ChangePassphrase(keyJSON, "foo", "bar", opts)
+		if err != nil {
+			t.Fatalf("%s: %v", opts.KDF, err)
+		}
+		if _, err := keystore.Import(changed, "foo"); err != keystore.ErrDecrypt {
+			t.Fatalf("%s: old passphrase still works: %v", opts.KDF, err)
+		}
+		imported, err = keystore.Import(changed, "bar")
+		if err != nil {
+			t.Fatalf("%s: %v", opts.KDF, err)
+		}
+		if imported.D.Cmp(key.D) != 0 {
+			t.Fatalf("%s: key differs after passphrase change", opts.KDF)
+		}
+		var m2 map[string]interface{}
+		if err := json.Unmarshal(changed, &m2); err != nil {
+			t.Fatal(err)
+		}
+		if m2["id"] != m["id"] || m2["address"] != m["address"] {
+			t.Fatalf("%s: id or address changed", opts.KDF)
+		}
+	}
+}
+
+func TestKeystoreHostileParams(t *testing.T) {
+	scryptJSON := func(params string) string {
+		return `{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"83dbcc02d8ccb40e466191a123791e0e"},"ciphertext":"d172bf743a674da9cdad04534d56926ef8358534d458fffccd4e6ad2fbde479c","kdf":"scrypt","kdfparams":{` +
+			params + `,"salt":"ab0c7876052600dd703518d6fc3fe8984592145b591fc8fb5c6d43190334ba19"},"mac":"2103ac29920d71da29f15d75b4a16dbe95cfd7ff8faea1056c33131d846e3097"},"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`
+	}
+	pbkdf2JSON := func(params string) string {
+		return `{"crypto":{"cipher":"aes-128-ctr","cipherparams":{"iv":"6087dab2f9fdbbfaddc31a909735c1e6"},"ciphertext":"5318b4d5bcd28de64ee5559e671353e16f075ecae9f99c7a79a38af5f869aa46","kdf":"pbkdf2","kdfparams":{` +
+			params + `,"prf":"hmac-sha256","salt":"ae3cd4e7013836a3df6bd7241b12db061dbe2c6785853cce422d148a624ce0bd"},"mac":"517ead924a9d0dc3124507e3393d175ce3ff7c1e96529c6c555ce9e51205e9b2"},"id":"3198bc9c-6672-5ab3-d995-4942343ae5b6","version":3}`
+	}
+	hostile := map[string]string{
+		"scrypt huge n":      scryptJSON(`"dklen":32,"n":1099511627776,"p":1,"r":8`),
+		"scrypt n not pow2":  scryptJSON(`"dklen":32,"n":262143,"p":1,"r":8`),
+		"scrypt huge r":      scryptJSON(`"dklen":32,"n":262144,"p":1,"r":1073741824`),
+		"scrypt huge p":      scryptJSON(`"dklen":32,"n":262144,"p":1073741823,"r":1`),
+		"scrypt memory":      scryptJSON(`"dklen":32,"n":1048576,"p":1,"r":64`),
+		"scrypt overflow":    scryptJSON(`"dklen":32,"n":1e300,"p":1,"r":8`),
+		"scrypt huge dklen":  scryptJSON(`"dklen":1073741824,"n":262144,"p":8,"r":1`),
+		"scrypt short dklen": scryptJSON(`"dklen":16,"n":262144,"p":8,"r":1`),
+		"pbkdf2 huge c":      pbkdf2JSON(`"c":1099511627776,"dklen":32`),
+		"pbkdf2 huge dklen":  pbkdf2JSON(`"c":262144,"dklen":1073741824`),
+	}
+	for name, keyJSON := range hostile {
+		if _, err := keystore.Import([]byte(keyJSON), "testpassword"); err == nil || err == keystore.ErrDecrypt {
+			t.Errorf("%s: err %v, want a parameter error", name, err)
+		}
+	}
+}
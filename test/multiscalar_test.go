@@ -0,0 +1,160 @@
+package test
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"github.com/w3liu/go-common/crypto/secp256k1"
+	"math/big"
+	"testing"
+)
+
+// randomPoints returns n random points and n random 32-byte scalars.
+func randomPoints(tb testing.TB, n int) ([]*big.Int, []*big.Int, [][]byte) {
+	curve := secp256k1.S256()
+	xs := make([]*big.Int, n)
+	ys := make([]*big.Int, n)
+	scalars := make([][]byte, n)
+	for i := 0; i < n; i++ {
+		k := make([]byte, 32)
+		if _, err := rand.Read(k); err != nil {
+			tb.Fatal(err)
+		}
+		xs[i], ys[i] = curve.ScalarBaseMult(k)
+		scalars[i] = make([]byte, 32)
+		if _, err := rand.Read(scalars[i]); err != nil {
+			tb.Fatal(err)
+		}
+	}
+	return xs, ys, scalars
+}
+
+func TestCombinedMult(t *testing.T) {
+	curve := secp256k1.S256()
+	xs, ys, scalars := randomPoints(t, 10)
+	extra := [][]byte{{}, {0x01}, curve.N.Bytes(), new(big.Int).Sub(curve.N, big.NewInt(1)).Bytes()}
+	for i := range xs {
+		u1 := scalars[i]
+		u2 := scalars[(i+1)%len(scalars)]
+		if i < len(extra) {
+			u1 = extra[i]
+		}
+		x1, y1 := curve.ScalarBaseMult(u1)
+		x2, y2 := curve.ScalarMult(xs[i], ys[i], u2)
+		wantX, wantY := curve.Add(x1, y1, x2, y2)
+		gotX, gotY := curve.CombinedMult(xs[i], ys[i], u1, u2)
+		if gotX.Cmp(wantX) != 0 || gotY.Cmp(wantY) != 0 {
+			t.Fatalf("u1=%x u2=%x: got (%x, %x), want (%x, %x)", u1, u2,
+				gotX, gotY, wantX, wantY)
+		}
+	}
+
+	// u*G + (N-u)*G is the point at infinity.
+	u := scalars[0]
+	negU := new(big.Int).Sub(curve.N, new(big.Int).SetBytes(u)).Bytes()
+	x, y := curve.CombinedMult(curve.Gx, curve.Gy, u, negU)
+	if x.Sign() != 0 || y.Sign() != 0 {
+		t.Fatalf("got (%x, %x), want the point at infinity", x, y)
+	}
+}
+
+func TestMultiScalarMult(t *testing.T) {
+	curve := secp256k1.S256()
+	// The sizes cover both the Strauss and the Pippenger code paths.
+	for _, n := range []int{1, 3, 40} {
+		xs, ys, scalars := randomPoints(t, n)
+		wantX, wantY := new(big.Int), new(big.Int)
+		for i := range xs {
+			x, y := curve.ScalarMult(xs[i], ys[i], scalars[i])
+			wantX, wantY = curve.Add(wantX, wantY, x, y)
+		}
+		gotX, gotY := curve.MultiScalarMult(xs, ys, scalars)
+		if gotX.Cmp(wantX) != 0 || gotY.Cmp(wantY) != 0 {
+			t.Fatalf("n=%d: got (%x, %x), want (%x, %x)", n, gotX, gotY, wantX, wantY)
+		}
+	}
+}
+
+// schnorrBatch returns n valid BIP-340 signatures made with random keys.
+func schnorrBatch(tb testing.TB, n int) ([][]byte, [][]byte, [][]byte) {
+	pubs := make([][]byte, n)
+	msgs := make([][]byte, n)
+	sigs := make([][]byte, n)
+	for i := 0; i < n; i++ {
+		priv, err := secp256k1.GeneratePrivateKey()
+		if err != nil {
+			tb.Fatal(err)
+		}
+		msg := sha256.Sum256([]byte{byte(i), byte(i >> 8)})
+		sig, err := secp256k1.SchnorrSign(priv.ToECDSA(), msg[:], nil)
+		if err != nil {
+			tb.Fatal(err)
+		}
+		pubs[i] = priv.PubKey().SerializeXOnly()
+		msgs[i] = msg[:]
+		sigs[i] = sig
+	}
+	return pubs, msgs, sigs
+}
+
+func TestSchnorrBatchVerifyLarge(t *testing.T) {
+	pubs, msgs, sigs := schnorrBatch(t, 40)
+	if !secp256k1.SchnorrBatchVerify(pubs, msgs, sigs) {
+		t.Fatal("valid batch does not verify")
+	}
+	msgs[39] = msgs[0]
+	if secp256k1.SchnorrBatchVerify(pubs, msgs, sigs) {
+		t.Fatal("invalid batch verifies")
+	}
+}
+
+func BenchmarkSeparateMult(b *testing.B) {
+	curve := secp256k1.S256()
+	xs, ys, scalars := randomPoints(b, 2)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		x1, y1 := curve.ScalarBaseMult(scalars[0])
+		x2, y2 := curve.ScalarMult(xs[0], ys[0], scalars[1])
+		curve.Add(x1, y1, x2, y2)
+	}
+}
+
+func BenchmarkCombinedMult(b *testing.B) {
+	curve := secp256k1.S256()
+	xs, ys, scalars := randomPoints(b, 2)
+	curve.CombinedMult(xs[0], ys[0], scalars[0], scalars[1])
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		curve.CombinedMult(xs[0], ys[0], scalars[0], scalars[1])
+	}
+}
+
+func benchmarkMultiScalarMult(b *testing.B, n int) {
+	curve := secp256k1.S256()
+	xs, ys, scalars := randomPoints(b, n)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		curve.MultiScalarMult(xs, ys, scalars)
+	}
+}
+
+func BenchmarkMultiScalarMult8(b *testing.B)   { benchmarkMultiScalarMult(b, 8) }
+func BenchmarkMultiScalarMult64(b *testing.B)  { benchmarkMultiScalarMult(b, 64) }
+func BenchmarkMultiScalarMult256(b *testing.B) { benchmarkMultiScalarMult(b, 256) }
+
+func BenchmarkSchnorrVerify64(b *testing.B) {
+	pubs, msgs, sigs := schnorrBatch(b, 64)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		for j := range sigs {
+			secp256k1.SchnorrVerify(pubs[j], msgs[j], sigs[j])
+		}
+	}
+}
+
+func BenchmarkSchnorrBatchVerify64(b *testing.B) {
+	pubs, msgs, sigs := schnorrBatch(b, 64)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		secp256k1.SchnorrBatchVerify(pubs, msgs, sigs)
+	}
+}
@@ -0,0 +1,311 @@
+package test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/w3liu/go-common/crypto/secp256k1"
+	"github.com/w3liu/go-common/crypto/secp256k1/musig2"
+	"strings"
+	"testing"
+)
+
+// BIP-327 test vectors, see
+// https://github.com/bitcoin/bips/tree/master/bip-0327/vectors
+var musig2PubKeys = []string{
+	"03935F972DA013F80AE011890FA89B67A27B7BE6CCB24D3274D18B2D4067F261A9",
+	"02F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9",
+	"02DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA661",
+}
+
+func hexList(t *testing.T, list []string, indices ...int) [][]byte {
+	out := make([][]byte, len(indices))
+	for i, idx := range indices {
+		out[i] = decodeHex(t, list[idx])
+	}
+	return out
+}
+
+func TestMuSig2KeyAgg(t *testing.T) {
+	pubKeys := []string{
+		"02F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9",
+		"03DFF1D77F2A671C5F36183726DB2341BE58FEAE1DA2DECED843240F7B502BA659",
+		"023590A94E768F8E1815C2F24B4D80A8E3149316C3518CE7B7AD338368D038CA66",
+		"020000000000000000000000000000000000000000000000000000000000000005",
+		"02FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC30",
+		"04F9308A019258C31049344F85F89D5229B531C845836F99B08601F113BCE036F9",
+	}
+	tests := []struct {
+		indices  []int
+		expected string
+	}{
+		{[]int{0, 1, 2}, "90539EEDE565F5D054F32CC0C220126889ED1E5D193BAF15AEF344FE59D4610C"},
+		{[]int{2, 1, 0}, "6204DE8B083426DC6EAF9502D27024D53FC826BF7D2012148A0575435DF54B2B"},
+		{[]int{0, 0, 0}, "B436E3BAD62B8CD409969A224731C193D051162D8C5AE8B109306127DA3AA935"},
+		{[]int{0, 0, 1, 1}, "69BC22BFA5D106306E48A20679DE1D7389386124D07571D0D872686028C26A3E"},
+		{[]int{0, 3}, ""},
+		{[]int{0, 4}, ""},
+		{[]int{5, 0}, ""},
+	}
+	for i, test := range tests {
+		key, err := musig2.AggregateKeys(hexList(t, pubKeys, test.indices...))
+		if test.expected == "" {
+			if err == nil {
+				t.Errorf("#%d: invalid keys aggregated", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if got := strings.ToUpper(hex.EncodeToString(key.XOnly())); got != test.expected {
+			t.Errorf("#%d: aggregate key %s, want %s", i, got, test.expected)
+		}
+	}
+
+	sorted := musig2.SortKeys(hexList(t, pubKeys, 1, 2, 0))
+	want := hexList(t, pubKeys, 2, 0, 1)
+	for i := range sorted {
+		if !bytes.Equal(sorted[i], want[i]) {
+			t.Fatalf("key %d sorted to %x, want %x", i, sorted[i], want[i])
+		}
+	}
+}
+
+func TestMuSig2NonceAgg(t *testing.T) {
+	pubNonces := []string{
+		"020151C80F435648DF67A22B749CD798CE54E0321D034B92B709B567D60A42E66603BA47FBC1834437B3212E89A84D8425E7BF12E0245D98262268EBDCB385D50641",
+		"03FF406FFD8ADB9CD29877E4985014F66A59F6CD01C0E88CAA8E5F3166B1F676A60248C264CDD57D3C24D79990B0F865674EB62A0F9018277A95011B41BFC193B833",
+		"020151C80F435648DF67A22B749CD798CE54E0321D034B92B709B567D60A42E6660279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+		"03FF406FFD8ADB9CD29877E4985014F66A59F6CD01C0E88CAA8E5F3166B1F676A60379BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+		"04FF406FFD8ADB9CD29877E4985014F66A59F6CD01C0E88CAA8E5F3166B1F676A60248C264CDD57D3C24D79990B0F865674EB62A0F9018277A95011B41BFC193B833",
+		"03FF406FFD8ADB9CD29877E4985014F66A59F6CD01C0E88CAA8E5F3166B1F676A60248C264CDD57D3C24D79990B0F865674EB62A0F9018277A95011B41BFC193B831",
+		"03FF406FFD8ADB9CD29877E4985014F66A59F6CD01C0E88CAA8E5F3166B1F676A602FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEFFFFFC30",
+	}
+	tests := []struct {
+		indices  []int
+		expected string
+	}{
+		{[]int{0, 1}, "035FE1873B4F2967F52FEA4A06AD5A8ECCBE9D0FD73068012C894E2E87CCB5804B024725377345BDE0E9C33AF3C43C0A29A9249F2F2956FA8CFEB55C8573D0262DC8"},
+		// The second points sum to the point at infinity.
+		{[]int{2, 3}, "035FE1873B4F2967F52FEA4A06AD5A8ECCBE9D0FD73068012C894E2E87CCB5804B000000000000000000000000000000000000000000000000000000000000000000"},
+		{[]int{0, 4}, ""},
+		{[]int{5, 1}, ""},
+		{[]int{6, 1}, ""},
+	}
+	for i, test := range tests {
+		aggNonce, err := musig2.AggregateNonces(hexList(t, pubNonces, test.indices...))
+		if test.expected == "" {
+			if err == nil {
+				t.Errorf("#%d: invalid nonces aggregated", i)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("#%d: %v", i, err)
+			continue
+		}
+		if got := strings.ToUpper(hex.EncodeToString(aggNonce)); got != test.expected {
+			t.Errorf("#%d: aggregate nonce %s, want %s", i, got, test.expected)
+		}
+	}
+}
+
+func TestMuSig2SignVerify(t *testing.T) {
+	privKey, err := secp256k1.PrivKeyFromBytes(decodeHex(t, "7FB9E0E687ADA1EEBF7ECFE2F21E73EBDB51A7D450948DFE8D76D7F2D1007671"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	secNonce := "508B81A611F100A6B2B6B29656590898AF488BCF2E1F55CF22E5CFB84421FE61FA27FD49B1D50085B481285E1CA205D55C82CC1B31FF5CD54A489829355901F703935F972DA013F80AE011890FA89B67A27B7BE6CCB24D3274D18B2D4067F261A9"
+	pubNonces := []string{
+		"0337C87821AFD50A8644D820A8F3E02E499C931865C2360FB43D0A0D20DAFE07EA0287BF891D2A6DEAEBADC909352AA9405D1428C15F4B75F04DAE642A95C2548480",
+		"0279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F817980279BE667EF9DCBBAC55A06295CE870B07029BFCDB2DCE28D959F2815B16F81798",
+		"032DE2662628C90B03F5E720284EB52FF7D71F4284F627B68A853D78C78E1FFE9303E4C5524E83FFE1493B9077CF1CA6BEB2090C93D930321071AD40B2F44E599046",
+		"0237C87821AFD50A8644D820A8F3E02E499C931865C2360FB43D0A0D20DAFE07EA0387BF891D2A6DEAEBADC909352AA9405D1428C15F4B75F04DAE642A95C2548480",
+	}
+	msg := decodeHex(t, "F95466D086770E689964664219266FE5ED215C92AE20BAB5C9D79ADDDDF3C0CF")
+	tests := []struct {
+		keyIndices   []int
+		nonceIndices []int
+		signerIndex  int
+		expected     string
+	}{
+		{[]int{0, 1, 2}, []int{0, 1, 2}, 0, "012ABBCB52B3016AC03AD82395A1A415C48B93DEF78718E62A7A90052FE224FB"},
+		{[]int{1, 0, 2}, []int{1, 0, 2}, 1, "9FF2F7AAA856150CC8819254218D3ADEEB0535269051897724F9DB3789513A52"},
+		{[]int{1, 2, 0}, []int{1, 2, 0}, 2, "FA23C359F6FAC4E7796BB93BC9F0532A95468C539BA20FF86D7C76ED92227900"},
+		// Both halves of the aggregate nonce are the point at infinity.
+		{[]int{0, 1}, []int{0, 3}, 0, "AE386064B26105404798F75DE2EB9AF5EDA5387B064B83D049CB7C5E08879531"},
+	}
+	for i, test := range tests {
+		key, err := musig2.AggregateKeys(hexList(t, musig2PubKeys, test.keyIndices...))
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		nonces := hexList(t, pubNonces, test.nonceIndices...)
+		aggNonce, err := musig2.AggregateNonces(nonces)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		session, err := musig2.NewSession(key, aggNonce, msg)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		partialSig, err := session.Sign(decodeHex(t, secNonce), privKey)
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if got := strings.ToUpper(hex.EncodeToString(partialSig)); got != test.expected {
+			t.Errorf("#%d: partial signature %s, want %s", i, got, test.expected)
+		}
+		pubKey := decodeHex(t, musig2PubKeys[0])
+		signerNonce := nonces[test.signerIndex]
+		if !session.VerifyPartial(partialSig, signerNonce, pubKey) {
+			t.Errorf("#%d: partial signature does not verify", i)
+		}
+		// A signature by the wrong signer or negated does not verify.
+		if session.VerifyPartial(partialSig, signerNonce, key.PubKey().SerializeCompressed()) {
+			t.Errorf("#%d: partial signature verifies for the wrong signer", i)
+		}
+		negated := new(secp256k1.Scalar).SetBytes(partialSig)
+		negated.Negate(negated)
+		if session.VerifyPartial(negated.Bytes(), signerNonce, pubKey) {
+			t.Errorf("#%d: negated partial signature verifies", i)
+		}
+	}
+
+	// The signer's key must be one of the aggregated keys.
+	key, err := musig2.AggregateKeys(hexList(t, musig2PubKeys, 1, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	aggNonce, err := musig2.AggregateNonces(hexList(t, pubNonces, 0, 1, 2))
+	if err != nil {
+		t.Fatal(err)
+	}
+	session, err := musig2.NewSession(key, aggNonce, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := session.Sign(decodeHex(t, secNonce), privKey); err != musig2.ErrSignerNotFound {
+		t.Fatalf("signing for a key set without the signer: %v", err)
+	}
+}
+
+func TestMuSig2SigAgg(t *testing.T) {
+	pubKeys := hexList(t, []string{
+		"03935F972DA013F80AE011890FA89B67A27B7BE6CCB24D3274D18B2D4067F261A9",
+		"02D2DC6F5DF7C56ACF38C7FA0AE7A759AE30E19B37359DFDE015872324C7EF6E05",
+	}, 0, 1)
+	key, err := musig2.AggregateKeys(pubKeys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg := decodeHex(t, "599C67EA410D005B9DA90817CF03ED3B1C868E4DA4EDF00A5880B0082C237869")
+	aggNonce := decodeHex(t, "0341432722C5CD0268D829C702CF0D1CBCE57033EED201FD335191385227C3210C03D377F2D258B64AADC0E16F26462323D701D286046A2EA93365656AFD9875982B")
+	session, err := musig2.NewSession(key, aggNonce, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := session.AggregatePartials(hexList(t, []string{
+		"B15D2CD3C3D22B04DAE438CE653F6B4ECF042F42CFDED7C41B64AAF9B4AF53FB",
+		"6193D6AC61B354E9105BBDC8937A3454A6D705B6D57322A5A472A02CE99FCB64",
+	}, 0, 1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "041DA22223CE65C92C9A0D6C2CAC828AAF1EEE56304FEC371DDF91EBB2B9EF0912F1038025857FEDEB3FF696F8B99FA4BB2C5812F6095A2E0004EC99CE18DE1E"
+	if got := strings.ToUpper(hex.EncodeToString(sig)); got != want {
+		t.Fatalf("signature %s, want %s", got, want)
+	}
+	if !secp256k1.SchnorrVerify(key.XOnly(), msg, sig) {
+		t.Fatal("aggregate signature does not verify")
+	}
+
+	if _, err := session.AggregatePartials(hexList(t, []string{
+		"B15D2CD3C3D22B04DAE438CE653F6B4ECF042F42CFDED7C41B64AAF9B4AF53FB",
+		"FFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFEBAAEDCE6AF48A03BBFD25E8CD0364141",
+	}, 0, 1)); err == nil {
+		t.Fatal("partial signature exceeding the group order aggregated")
+	}
+}
+
+// TestMuSig2Signing runs a full signing session between several parties.
+func TestMuSig2Signing(t *testing.T) {
+	const n = 3
+	msg := []byte("transfer 10 BTC to cold storage")
+
+	privKeys := make([]*secp256k1.PrivateKey, n)
+	pubKeys := make([][]byte, n)
+	for i := range privKeys {
+		privKey, err := secp256k1.GeneratePrivateKey()
+		if err != nil {
+			t.Fatal(err)
+		}
+		privKeys[i] = privKey
+		pubKeys[i] = privKey.PubKey().SerializeCompressed()
+	}
+	key, err := musig2.AggregateKeys(musig2.SortKeys(pubKeys))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Round one: every signer publishes a public nonce.
+	secNonces := make([][]byte, n)
+	pubNonces := make([][]byte, n)
+	for i := range privKeys {
+		secNonces[i], pubNonces[i], err = musig2.GenerateNonce(pubKeys[i], musig2.NonceOptions{
+			PrivKey: privKeys[i],
+			AggKey:  key,
+			Msg:     msg,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	aggNonce, err := musig2.AggregateNonces(pubNonces)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Round two: every signer publishes a partial signature.
+	partialSigs := make([][]byte, n)
+	for i := range privKeys {
+		session, err := musig2.NewSession(key, aggNonce, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if partialSigs[i], err = session.Sign(secNonces[i], privKeys[i]); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := session.Sign(secNonces[i], privKeys[i]); err != musig2.ErrNonceReused {
+			t.Fatalf("signer %d reused its nonce: %v", i, err)
+		}
+	}
+
+	// Anyone can check the partial signatures and aggregate them.
+	session, err := musig2.NewSession(key, aggNonce, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range partialSigs {
+		if !session.VerifyPartial(partialSigs[i], pubNonces[i], pubKeys[i]) {
+			t.Fatalf("partial signature %d does not verify", i)
+		}
+	}
+	sig, err := session.AggregatePartials(partialSigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !secp256k1.SchnorrVerify(key.XOnly(), msg, sig) {
+		t.Fatal("aggregate signature does not verify")
+	}
+
+	// Without every partial signature the result is not a valid signature.
+	sig, err = session.AggregatePartials(partialSigs[:n-1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secp256k1.SchnorrVerify(key.XOnly(), msg, sig) {
+		t.Fatal("signature missing a partial signature verifies")
+	}
+}
@@ -0,0 +1,150 @@
+package test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"github.com/w3liu/go-common/crypto/secp256k1"
+	"github.com/w3liu/go-common/crypto/secp256k1/pedersen"
+	"testing"
+)
+
+func TestPointArithmetic(t *testing.T) {
+	curve := secp256k1.S256()
+	g := secp256k1.NewGenerator()
+	a, err := secp256k1.RandomScalar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := secp256k1.RandomScalar()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a*G + b*G == (a+b)*G
+	var aG, bG, sum, want secp256k1.Point
+	aG.ScalarBaseMult(a)
+	bG.ScalarBaseMult(b)
+	sum.Add(&aG, &bG)
+	want.ScalarBaseMult(new(secp256k1.Scalar).Add(a, b))
+	if !sum.Equals(&want) {
+		t.Fatal("a*G + b*G != (a+b)*G")
+	}
+
+	// The point API agrees with the big.Int API.
+	x, y := sum.AffineCoords()
+	wantX, wantY := curve.ScalarBaseMult(new(secp256k1.Scalar).Add(a, b).Bytes())
+	if x.Cmp(wantX) != 0 || y.Cmp(wantY) != 0 {
+		t.Fatal("affine coordinates differ from ScalarBaseMult")
+	}
+
+	// a*(b*G) == (a*b)*G, in constant and variable time.
+	var abG, abGct secp256k1.Point
+	abG.ScalarMult(a, &bG)
+	abGct.ScalarMultConstantTime(a, &bG)
+	want.ScalarBaseMultConstantTime(new(secp256k1.Scalar).Mul(a, b))
+	if !abG.Equals(&want) || !abGct.Equals(&want) {
+		t.Fatal("a*(b*G) != (a*b)*G")
+	}
+
+	// G + G == 2*G, P - P == ∞ and P + ∞ == P.
+	var twoG, diff, same secp256k1.Point
+	twoG.Double(g)
+	want.ScalarBaseMult(secp256k1.NewScalar(2))
+	if !twoG.Equals(&want) || !new(secp256k1.Point).Add(g, g).Equals(&want) {
+		t.Fatal("G + G != 2*G")
+	}
+	diff.Sub(&aG, &aG)
+	if !diff.IsInfinity() {
+		t.Fatal("P - P is not the point at infinity")
+	}
+	same.Add(&aG, &diff)
+	if !same.Equals(&aG) || same.Equals(&bG) {
+		t.Fatal("P + ∞ != P")
+	}
+
+	// -P == (N-a)*G
+	var negA secp256k1.Point
+	negA.Negate(&aG)
+	want.ScalarBaseMult(new(secp256k1.Scalar).Negate(a))
+	if !negA.Equals(&want) {
+		t.Fatal("-(a*G) != (-a)*G")
+	}
+
+	// a * a^-1 == 1
+	if !new(secp256k1.Scalar).Mul(a, new(secp256k1.Scalar).Inverse(a)).Equals(secp256k1.NewScalar(1)) {
+		t.Fatal("a * a^-1 != 1")
+	}
+}
+
+func TestPointSerialization(t *testing.T) {
+	g := secp256k1.NewGenerator()
+	want := "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+	if got := hex.EncodeToString(g.SerializeCompressed()); got != want {
+		t.Fatalf("serialized G %s, want %s", got, want)
+	}
+
+	a, err := secp256k1.RandomScalar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var p secp256k1.Point
+	p.ScalarBaseMult(a).Double(&p)
+	for _, b := range [][]byte{p.SerializeCompressed(), p.PubKey().SerializeUncompressed()} {
+		parsed, err := secp256k1.ParsePoint(b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !parsed.Equals(&p) {
+			t.Fatalf("%x: parsed point differs", b)
+		}
+	}
+
+	inf := new(secp256k1.Point)
+	if !bytes.Equal(inf.SerializeCompressed(), []byte{0x00}) {
+		t.Fatal("point at infinity does not serialize to 0x00")
+	}
+	parsed, err := secp256k1.ParsePoint([]byte{0x00})
+	if err != nil || !parsed.IsInfinity() {
+		t.Fatalf("0x00 does not parse to the point at infinity: %v", err)
+	}
+
+	s, err := secp256k1.ParseScalar(a.Bytes())
+	if err != nil || !s.Equals(a) {
+		t.Fatalf("scalar round trip failed: %v", err)
+	}
+	if _, err := secp256k1.ParseScalar(secp256k1.S256().N.Bytes()); err == nil {
+		t.Fatal("N parsed as a scalar")
+	}
+}
+
+func TestPedersen(t *testing.T) {
+	// H is the BIP-341 NUMS point.
+	wantH := "0250929b74c1a04954b78b4b6035e97a5e078a5a0f28ec96d547bfee9ace803ac0"
+	if got := hex.EncodeToString(pedersen.H().SerializeCompressed()); got != wantH {
+		t.Fatalf("H %s, want %s", got, wantH)
+	}
+
+	r1, err := secp256k1.RandomScalar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	r2, err := secp256k1.RandomScalar()
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1, v2 := secp256k1.NewScalar(40), secp256k1.NewScalar(2)
+	c1 := pedersen.Commit(v1, r1)
+	c2 := pedersen.Commit(v2, r2)
+	if !pedersen.Open(c1, v1, r1) {
+		t.Fatal("commitment does not open")
+	}
+	if pedersen.Open(c1, v2, r1) || pedersen.Open(c1, v1, r2) {
+		t.Fatal("commitment opens to the wrong value")
+	}
+
+	// C(v1, r1) + C(v2, r2) == C(v1+v2, r1+r2)
+	sum := new(secp256k1.Point).Add(c1, c2)
+	if !pedersen.Open(sum, secp256k1.NewScalar(42), new(secp256k1.Scalar).Add(r1, r2)) {
+		t.Fatal("commitments are not homomorphic")
+	}
+}
@@ -1,6 +1,7 @@
 package test
 
 import (
+	"bytes"
 	"fmt"
 	"github.com/w3liu/go-common/crypto/secp256k1"
 	"testing"
@@ -13,3 +14,87 @@ func TestGenkey(t *testing.T) {
 	}
 	fmt.Println(key)
 }
+
+func TestPubKeySerialize(t *testing.T) {
+	const (
+		compressed   = "0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798"
+		uncompressed = "0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8"
+		hybrid       = "0679be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8"
+	)
+	priv, err := secp256k1.PrivKeyFromBytes(decodeHex(t, "0000000000000000000000000000000000000000000000000000000000000001"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pub := priv.PubKey()
+	if got := pub.SerializeCompressed(); !bytes.Equal(got, decodeHex(t, compressed)) {
+		t.Fatalf("compressed %x", got)
+	}
+	if got := pub.SerializeUncompressed(); !bytes.Equal(got, decodeHex(t, uncompressed)) {
+		t.Fatalf("uncompressed %x", got)
+	}
+	if got := pub.SerializeHybrid(); !bytes.Equal(got, decodeHex(t, hybrid)) {
+		t.Fatalf("hybrid %x", got)
+	}
+	for _, s := range []string{compressed, uncompressed, hybrid} {
+		parsed, err := secp256k1.ParsePubKey(decodeHex(t, s))
+		if err != nil {
+			t.Fatalf("%s: %v", s, err)
+		}
+		if !parsed.IsEqual(pub) {
+			t.Fatalf("%s: parsed a different key", s)
+		}
+	}
+}
+
+func TestParsePubKeyInvalid(t *testing.T) {
+	tests := []string{
+		"",
+		// bad magic
+		"0579be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798",
+		// not on the curve
+		"0479be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b9",
+		// hybrid with the wrong y bit
+		"0779be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f81798483ada7726a3c4655da4fbfc0e1108a8fd17b448a68554199c47d08ffb10d4b8",
+		// x >= P
+		"02fffffffffffffffffffffffffffffffffffffffffffffffffffffffefffffc30",
+		// x^3 + 7 has no square root
+		"020000000000000000000000000000000000000000000000000000000000000005",
+		// wrong length
+		"0279be667ef9dcbbac55a06295ce870b07029bfcdb2dce28d959f2815b16f817",
+	}
+	for _, s := range tests {
+		if _, err := secp256k1.ParsePubKey(decodeHex(t, s)); err == nil {
+			t.Fatalf("%s: expected error", s)
+		}
+	}
+}
+
+func TestPrivKeyFromBytes(t *testing.T) {
+	key, err := secp256k1.GeneratePrivateKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := key.Serialize()
+	if len(b) != secp256k1.PrivKeyBytesLen {
+		t.Fatalf("serialized length %d", len(b))
+	}
+	parsed, err := secp256k1.PrivKeyFromBytes(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.D.Cmp(key.D) != 0 || !parsed.PubKey().IsEqual(key.PubKey()) {
+		t.Fatal("round trip produced a different key")
+	}
+
+	invalid := []string{
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		"fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141",
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		"01",
+	}
+	for _, s := range invalid {
+		if _, err := secp256k1.PrivKeyFromBytes(decodeHex(t, s)); err == nil {
+			t.Fatalf("%s: expected error", s)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/w3liu/go-common/crypto/secp256k1"
+	"math/big"
+	"testing"
+)
+
+func decodeHex(t *testing.T, s string) []byte {
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}
+
+func privKeyFromHex(t *testing.T, s string) *ecdsa.PrivateKey {
+	curve := secp256k1.S256()
+	d := decodeHex(t, s)
+	x, y := curve.ScalarBaseMult(d)
+	return &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{Curve: curve, X: x, Y: y},
+		D:         new(big.Int).SetBytes(d),
+	}
+}
+
+func TestSignRFC6979(t *testing.T) {
+	// Test vectors matching Trezor and CoreBitcoin implementations.
+	tests := []struct {
+		key       string
+		msg       string
+		signature string
+	}{
+		{
+			"cca9fbcc1b41e5a95d369eaa6ddcff73b61a4efaa279cfc6567e8daa39cbaf50",
+			"sample",
+			"af340daf02cc15c8d5d08d7735dfe6b98a474ed373bdb5fbecf7571be52b38425009fb27f37034a9b24b707b7c6b79ca23ddef9e25f7282e8a797efe53a8f124",
+		},
+		{
+			// S is higher than half the order before normalization.
+			"0000000000000000000000000000000000000000000000000000000000000001",
+			"Satoshi Nakamoto",
+			"934b1ea10a4b3c1757e2b0c017d0b6143ce3c9a7e6a4a49860d7a6ab210ee3d82442ce9d2b916064108014783e923ec36b49743e2ffa1c4496f01a512aafd9e5",
+		},
+		{
+			"fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364140",
+			"Satoshi Nakamoto",
+			"fd567d121db66e382991534ada77a6bd3106f0a1098c231e47993447cd6af2d06b39cd0eb1bc8603e159ef5c20a5c8ad685a45b06ce9bebed3f153d10d93bed5",
+		},
+		{
+			"f8b8af8ce3c7cca5e300d33939540c10d45ce001b8f252bfbc57ba0342904181",
+			"Alan Turing",
+			"7063ae83e7f62bbb171798131b4a0564b956930092b33b07b395615d9ec7e15c58dfcc1e00a35e1572f366ffe34ba0fc47db1e7189759b9fb233c5b05ab388ea",
+		},
+	}
+
+	for i, test := range tests {
+		key := privKeyFromHex(t, test.key)
+		hash := sha256.Sum256([]byte(test.msg))
+		sig, err := secp256k1.Sign(key, hash[:])
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if want := decodeHex(t, test.signature); !bytes.Equal(sig, want) {
+			t.Fatalf("#%d: signature %x, want %x", i, sig, want)
+		}
+		if !secp256k1.Verify(&key.PublicKey, hash[:], sig) {
+			t.Fatalf("#%d: signature does not verify", i)
+		}
+		hash[0] ^= 0xff
+		if secp256k1.Verify(&key.PublicKey, hash[:], sig) {
+			t.Fatalf("#%d: signature verifies for a different hash", i)
+		}
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	key, err := secp256k1.NewPrivateKey(secp256k1.S256())
+	if err != nil {
+		t.Fatal(err)
+	}
+	hash := sha256.Sum256([]byte("hello"))
+	sig, err := secp256k1.Sign(key, hash[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sig) != secp256k1.SignatureSize {
+		t.Fatalf("signature length %d", len(sig))
+	}
+	if !secp256k1.Verify(&key.PublicKey, hash[:], sig) {
+		t.Fatal("signature does not verify")
+	}
+
+	// The high-S form of the same signature is still valid ECDSA, but Sign
+	// must never produce it.
+	s := new(big.Int).SetBytes(sig[32:])
+	if s.Cmp(new(big.Int).Rsh(secp256k1.S256().N, 1)) > 0 {
+		t.Fatal("signature s is not normalized")
+	}
+}
+
+func TestSignCompact(t *testing.T) {
+	for i := 0; i < 10; i++ {
+		key, err := secp256k1.NewPrivateKey(secp256k1.S256())
+		if err != nil {
+			t.Fatal(err)
+		}
+		hash := sha256.Sum256([]byte{byte(i)})
+		compressed := i%2 == 0
+		sig, err := secp256k1.SignCompact(key, hash[:], compressed)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(sig) != secp256k1.CompactSignatureSize {
+			t.Fatalf("#%d: signature length %d", i, len(sig))
+		}
+		pub, wasCompressed, err := secp256k1.RecoverCompact(sig, hash[:])
+		if err != nil {
+			t.Fatalf("#%d: %v", i, err)
+		}
+		if pub.X.Cmp(key.X) != 0 || pub.Y.Cmp(key.Y) != 0 {
+			t.Fatalf("#%d: recovered a different public key", i)
+		}
+		if wasCompressed != compressed {
+			t.Fatalf("#%d: compressed flag %v, want %v", i, wasCompressed, compressed)
+		}
+		if !secp256k1.Verify(pub, hash[:], sig[1:]) {
+			t.Fatalf("#%d: signature does not verify", i)
+		}
+
+		// A recovery against another hash must not yield the signer.
+		hash[0] ^= 0xff
+		pub, _, err = secp256k1.RecoverCompact(sig, hash[:])
+		if err == nil && pub.X.Cmp(key.X) == 0 && pub.Y.Cmp(key.Y) == 0 {
+			t.Fatalf("#%d: recovered signer for a different hash", i)
+		}
+	}
+
+	if _, _, err := secp256k1.RecoverCompact(make([]byte, 64), make([]byte, 32)); err == nil {
+		t.Fatal("expected error for short signature")
+	}
+}
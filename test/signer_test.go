@@ -0,0 +1,179 @@
+package test
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/w3liu/go-common/crypto/secp256k1"
+	"github.com/w3liu/go-common/crypto/signer"
+	"math/big"
+	"testing"
+)
+
+func TestSigner(t *testing.T) {
+	msg := []byte("switch algorithms by configuration")
+	for _, name := range []string{"secp256k1", "p256", "ed25519"} {
+		keyType, err := signer.ParseKeyType(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if keyType.String() != name {
+			t.Errorf("%s: key type name %s", name, keyType)
+		}
+		s, err := signer.Generate(keyType)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		sig, err := s.Sign(msg)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if len(sig) != signer.SignatureSize {
+			t.Fatalf("%s: signature length %d", name, len(sig))
+		}
+		if !s.Public().Verify(msg, sig) {
+			t.Fatalf("%s: signature does not verify", name)
+		}
+		if s.Public().Verify([]byte("another message"), sig) {
+			t.Fatalf("%s: signature verifies for another message", name)
+		}
+
+		// Keys and signatures survive encoding.
+		decodedSigner, err := signer.DecodePrivateKey(signer.EncodePrivateKey(s))
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if !bytes.Equal(decodedSigner.Public().Bytes(), s.Public().Bytes()) {
+			t.Fatalf("%s: decoded private key has another public key", name)
+		}
+		pubKey := signer.EncodePublicKey(s.Public())
+		v, err := signer.DecodePublicKey(pubKey)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if v.Type() != keyType || !v.Verify(msg, sig) {
+			t.Fatalf("%s: decoded public key does not verify", name)
+		}
+		encodedSig := signer.EncodeSignature(keyType, sig)
+		if !signer.Verify(pubKey, msg, encodedSig) {
+			t.Fatalf("%s: encoded signature does not verify", name)
+		}
+
+		// A signature tagged with another key type is rejected.
+		encodedSig[0] = byte(signer.Secp256k1 + signer.Ed25519 - keyType)
+		if keyType != signer.P256 && signer.Verify(pubKey, msg, encodedSig) {
+			t.Fatalf("%s: signature with the wrong key type verifies", name)
+		}
+	}
+
+	if _, err := signer.ParseKeyType("rsa"); err != signer.ErrUnknownKeyType {
+		t.Fatalf("unknown key type parsed: %v", err)
+	}
+	if _, err := signer.DecodePublicKey([]byte{0x7f, 0x01}); err != signer.ErrUnknownKeyType {
+		t.Fatalf("public key with unknown key type decoded: %v", err)
+	}
+}
+
+func TestSignerP256Compression(t *testing.T) {
+	msg := []byte("p256")
+	hash := sha256.Sum256(msg)
+	for i := 0; i < 16; i++ {
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		s, err := signer.NewSigner(signer.P256, key.D.Bytes())
+		if err != nil {
+			// A scalar with leading zero bytes is too short.
+			continue
+		}
+		v, err := signer.NewVerifier(signer.P256, s.Public().Bytes())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// The decompressed key verifies signatures by the original key.
+		r, sigS, err := ecdsa.Sign(rand.Reader, key, hash[:])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if sigS.Cmp(new(big.Int).Rsh(key.Params().N, 1)) > 0 {
+			sigS.Sub(key.Params().N, sigS)
+		}
+		sig := make([]byte, signer.SignatureSize)
+		copy(sig[32-len(r.Bytes()):], r.Bytes())
+		copy(sig[64-len(sigS.Bytes()):], sigS.Bytes())
+		if !v.Verify(msg, sig) {
+			t.Fatalf("%x: signature does not verify after decompression", s.Public().Bytes())
+		}
+	}
+
+	// A point that is not on the curve is rejected.
+	bad := make([]byte, 33)
+	bad[0] = 0x02
+	bad[32] = 0x01
+	if _, err := signer.NewVerifier(signer.P256, bad); err == nil {
+		t.Fatal("invalid public key accepted")
+	}
+}
+
+// TestSignerLowS checks that ECDSA signatures are canonical for both curves:
+// Sign only produces low-S signatures and Verify rejects the high-S form of a
+// valid signature.
+func TestSignerLowS(t *testing.T) {
+	orders := map[signer.KeyType]*big.Int{
+		signer.Secp256k1: secp256k1.S256().N,
+		signer.P256:      elliptic.P256().Params().N,
+	}
+	msg := []byte("low s")
+	for kt, n := range orders {
+		half := new(big.Int).Rsh(n, 1)
+		s, err := signer.Generate(kt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for i := 0; i < 32; i++ {
+			sig, err := s.Sign(msg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			sigS := new(big.Int).SetBytes(sig[32:])
+			if sigS.Cmp(half) > 0 {
+				t.Fatalf("%s: high-S signature %x", kt, sig)
+			}
+			if !s.Public().Verify(msg, sig) {
+				t.Fatalf("%s: signature %x does not verify", kt, sig)
+			}
+			high := append([]byte(nil), sig[:32]...)
+			highS := new(big.Int).Sub(n, sigS).Bytes()
+			high = append(high, make([]byte, 32-len(highS))...)
+			high = append(high, highS...)
+			if s.Public().Verify(msg, high) {
+				t.Fatalf("%s: high-S form of %x verifies", kt, sig)
+			}
+		}
+	}
+}
+
+// TestSignerEd25519 checks test 1 of RFC 8032 section 7.1.
+func TestSignerEd25519(t *testing.T) {
+	s, err := signer.NewSigner(signer.Ed25519, decodeHex(t, "9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantPub := "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"
+	if got := hex.EncodeToString(s.Public().Bytes()); got != wantPub {
+		t.Fatalf("public key %s, want %s", got, wantPub)
+	}
+	sig, err := s.Sign(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantSig := "e5564300c360ac729086e2cc806e828a84877f1eb8e5d974d873e065224901555fb8821590a33bacc61e39701cf9b46bd25bf5f0595bbe24655141438e7a100b"
+	if got := hex.EncodeToString(sig); got != wantSig {
+		t.Fatalf("signature %s, want %s", got, wantSig)
+	}
+}